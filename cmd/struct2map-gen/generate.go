@@ -0,0 +1,546 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iancoleman/strcase"
+	"github.com/newodahs/struct2map/internal"
+)
+
+const struct2mapImport = "github.com/newodahs/struct2map/pkg"
+
+// a case modifier as applied by one of the STRUCT_CONVERT_MAPKEY_* options
+type caseModifier struct {
+	option string // name of the matching StructConvertOpts constant
+	modify func(string) string
+}
+
+var caseModifiers = map[string]caseModifier{
+	"lower":      {option: "STRUCT_CONVERT_MAPKEY_TOLOWER", modify: strings.ToLower},
+	"upper":      {option: "STRUCT_CONVERT_MAPKEY_TOUPPER", modify: strings.ToUpper},
+	"camel":      {option: "STRUCT_CONVERT_MAPKEY_CAMELCASE", modify: strcase.ToCamel},
+	"lowercamel": {option: "STRUCT_CONVERT_MAPKEY_LOWERCAMEL", modify: strcase.ToLowerCamel},
+	"snake":      {option: "STRUCT_CONVERT_MAPKEY_SNAKE", modify: strcase.ToSnake},
+}
+
+// how the generated code converts a field
+type fieldKind uint
+
+const (
+	kindFallback   fieldKind = iota // converted reflectively through struct2map.ConvertValueInto
+	kindBasic                       // bool, string and numeric values, stored as they are
+	kindPtrBasic                    // a single pointer to a basic value, dereferenced
+	kindSliceBasic                  // slices of basic values, one key per index
+	kindStruct                      // a structure type of the same package, through its generated helper
+	kindPtrStruct                   // a single pointer to a structure type of the same package
+)
+
+// a structure field as the generated code sees it
+type genField struct {
+	goName        string // name of the field in Go
+	key           string // key segment the field produces
+	kind          fieldKind
+	typ           types.Type
+	omitEmpty     bool
+	ignoreParents bool
+	redact        bool
+	hasDefault    bool
+	defaultLit    string // literal of the default tag option, if any
+}
+
+type generator struct {
+	pkg     *types.Package
+	caseMod *caseModifier
+	helper  string            // name of the generated helper method
+	imports map[string]string // import path to package name, for the type expressions FromMap needs
+	buf     bytes.Buffer
+}
+
+// Parses the package in dir (ignoring test files and skipFile, the file being generated) and generates the methods
+// for typeNames.
+//
+// Returns: the gofmt'd source of the generated file or an error if a type cannot be found or is not a structure
+func generate(dir, skipFile string, typeNames []string, caseName string) ([]byte, error) {
+	pkg, err := loadPackage(dir, skipFile)
+	if err != nil {
+		return nil, err
+	}
+
+	gen := &generator{pkg: pkg, helper: "struct2mapInto", imports: make(map[string]string)}
+	if caseName != "" {
+		caseMod, ok := caseModifiers[caseName]
+		if !ok {
+			return nil, fmt.Errorf("unknown case modifier %q", caseName)
+		}
+		gen.caseMod = &caseMod
+		gen.helper = fmt.Sprintf("struct2map%sInto", strcase.ToCamel(caseName)) // keeps helpers of differing modifiers apart
+	}
+
+	// every requested type plus every structure type of the package reachable from them gets the helper
+	var roots, pending []*types.Named
+	seen := make(map[*types.Named]bool)
+	for _, typeName := range typeNames {
+		obj := pkg.Scope().Lookup(strings.TrimSpace(typeName))
+		if obj == nil {
+			return nil, fmt.Errorf("type %s not found in %s", typeName, pkg.Name())
+		}
+
+		named, ok := obj.Type().(*types.Named)
+		if !ok || !gen.isLocalStruct(named) {
+			return nil, fmt.Errorf("type %s is not a structure", typeName)
+		}
+
+		roots = append(roots, named)
+		if !seen[named] {
+			seen[named] = true
+			pending = append(pending, named)
+		}
+	}
+
+	for idx := 0; idx < len(pending); idx++ {
+		// defaults are checked up front so writing the helpers cannot fail
+		for _, field := range gen.fields(pending[idx]) {
+			if !field.hasDefault {
+				continue
+			}
+			if _, err := gen.defaultExpr(field); err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", pending[idx].Obj().Name(), field.goName, err)
+			}
+		}
+
+		gen.writeHelper(pending[idx])
+		for _, field := range gen.fields(pending[idx]) {
+			if field.kind != kindStruct && field.kind != kindPtrStruct {
+				continue
+			}
+
+			named := gen.structOf(field.typ)
+			if !seen[named] {
+				seen[named] = true
+				pending = append(pending, named)
+			}
+		}
+	}
+
+	for _, named := range roots {
+		gen.writeToMap(named)
+		gen.writeFromMap(named)
+	}
+
+	return gen.source()
+}
+
+func loadPackage(dir, skipFile string) (*types.Package, error) {
+	fileNames, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, fileName := range fileNames {
+		if strings.HasSuffix(fileName, "_test.go") || filepath.Base(fileName) == skipFile {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, fileName, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		if len(files) > 0 && file.Name.Name != files[0].Name.Name {
+			continue
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Go files found in %s", dir)
+	}
+
+	// type errors (ex: references to a previously generated file being replaced) do not matter here; only the
+	// declarations of the structure types do, which are complete regardless
+	conf := types.Config{Importer: importer.ForCompiler(fset, "gc", nil), Error: func(error) {}}
+	pkg, _ := conf.Check(files[0].Name.Name, fset, files, nil)
+
+	return pkg, nil
+}
+
+// the fields of a structure type as ConvertStruct sees them; unexported and "-" fields are left out
+func (g *generator) fields(named *types.Named) []genField {
+	structType := named.Underlying().(*types.Struct)
+
+	var ret []genField
+	for pos := 0; pos < structType.NumFields(); pos++ {
+		field := structType.Field(pos)
+		if !field.Exported() {
+			continue
+		}
+
+		genF := genField{goName: field.Name(), key: field.Name(), typ: field.Type(), kind: g.kindOf(field.Type())}
+		if tagVal, ok := reflect.StructTag(structType.Tag(pos)).Lookup(internal.STRUCT_MAP_PRIMARY_TAGNAME); ok {
+			tagSplit := strings.Split(tagVal, ",")
+			if tagSplit[0] == "-" {
+				continue
+			}
+
+			genF.key = tagSplit[0]
+			for _, opt := range tagSplit[1:] {
+				switch opt {
+				case internal.STRUCT_MAP_TAG_OMIT:
+					genF.omitEmpty = true
+				case internal.STRUCT_MAP_TAG_IGNORE_PARENT:
+					genF.ignoreParents = true
+				case internal.STRUCT_MAP_TAG_REDACT:
+					genF.redact = true
+				default:
+					if defaultLit, found := strings.CutPrefix(opt, internal.STRUCT_MAP_TAG_DEFAULT+"="); found {
+						genF.hasDefault = true
+						genF.defaultLit = defaultLit
+					}
+				}
+			}
+		}
+
+		if g.caseMod != nil {
+			genF.key = g.caseMod.modify(field.Name())
+		}
+
+		ret = append(ret, genF)
+	}
+
+	return ret
+}
+
+func (g *generator) kindOf(typ types.Type) fieldKind {
+	switch {
+	case isBasic(typ):
+		return kindBasic
+	case g.structOf(typ) != nil:
+		if _, isPtr := typ.(*types.Pointer); isPtr {
+			return kindPtrStruct
+		}
+		return kindStruct
+	}
+
+	switch typ := typ.Underlying().(type) {
+	case *types.Pointer:
+		if isBasic(typ.Elem()) {
+			return kindPtrBasic
+		}
+	case *types.Slice:
+		if isBasic(typ.Elem()) {
+			return kindSliceBasic
+		}
+	}
+
+	return kindFallback
+}
+
+// the structure type of the package typ is (or typ points to directly), if any
+func (g *generator) structOf(typ types.Type) *types.Named {
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+
+	named, ok := typ.(*types.Named)
+	if !ok || !g.isLocalStruct(named) {
+		return nil
+	}
+
+	return named
+}
+
+func (g *generator) isLocalStruct(named *types.Named) bool {
+	_, isStruct := named.Underlying().(*types.Struct)
+	return isStruct && named.Obj().Pkg() == g.pkg && named.TypeParams().Len() == 0
+}
+
+// true for the types ConvertStruct stores as they are
+func isBasic(typ types.Type) bool {
+	basic, ok := typ.Underlying().(*types.Basic)
+	return ok && basic.Info()&(types.IsBoolean|types.IsNumeric|types.IsString) != 0 && basic.Info()&types.IsUntyped == 0
+}
+
+// the helper every ToMap is built on; writes the fields of v into m with every key prefixed by prefix
+func (g *generator) writeHelper(named *types.Named) {
+	typeName := named.Obj().Name()
+	g.printf("func (v *%s) %s(m map[string]any, prefix string) {\n", typeName, g.helper)
+	for _, field := range g.fields(named) {
+		// as ConvertStruct does, ignoring parents drops the prefix for this field and every field after it
+		if field.ignoreParents {
+			g.printf("prefix = \"\"\n")
+		}
+
+		key := fmt.Sprintf("prefix+%q", field.key)
+		switch {
+		case field.redact:
+			g.printf("m[%s] = struct2map.DEFAULT_REDACT_MASK\n", key)
+		case field.hasDefault && field.kind == kindBasic:
+			defaultExpr, _ := g.defaultExpr(field)
+			g.printf("if %s {\nm[%s] = %s\n} else {\nm[%s] = v.%s\n}\n", zeroCheck(field), key, defaultExpr, key, field.goName)
+		case field.hasDefault && field.kind == kindPtrBasic:
+			defaultExpr, _ := g.defaultExpr(field)
+			g.printf("if v.%s != nil {\nm[%s] = *v.%s\n} else {\nm[%s] = %s\n}\n", field.goName, key, field.goName, key, defaultExpr)
+		case field.kind == kindBasic:
+			g.printf("m[%s] = v.%s\n", key, field.goName)
+		case field.kind == kindPtrBasic:
+			g.printf("if v.%s != nil {\nm[%s] = *v.%s\n}", field.goName, key, field.goName)
+			g.printNilElse(field, key)
+		case field.kind == kindSliceBasic:
+			g.imports["strconv"] = "strconv"
+			g.printf("for idx, item := range v.%s {\nm[prefix+%q+strconv.Itoa(idx)] = item\n}\n", field.goName, field.key+".")
+		case field.kind == kindStruct:
+			g.printf("v.%s.%s(m, prefix+%q)\n", field.goName, g.helper, field.key+".")
+		case field.kind == kindPtrStruct:
+			g.printf("if v.%s != nil {\nv.%s.%s(m, prefix+%q)\n}", field.goName, field.goName, g.helper, field.key+".")
+			g.printNilElse(field, key)
+		case types.IsInterface(field.typ):
+			// passed by address so the value is converted as the interface it is, rather than as what it holds
+			g.printf("struct2map.ConvertValueInto(m, %s, &v.%s, %t%s)\n", key, field.goName, field.omitEmpty, g.optsArg())
+		default:
+			g.printf("struct2map.ConvertValueInto(m, %s, v.%s, %t%s)\n", key, field.goName, field.omitEmpty, g.optsArg())
+		}
+	}
+	g.printf("}\n\n")
+}
+
+// the Go expression for the default of a field, parsed as ConvertStruct parses it; only basic fields (and single
+// pointers to them) are supported
+func (g *generator) defaultExpr(field genField) (string, error) {
+	typ := field.typ
+	if field.kind == kindPtrBasic {
+		typ = typ.Underlying().(*types.Pointer).Elem()
+	} else if field.kind != kindBasic {
+		return "", fmt.Errorf("default is only supported on bool, string and numeric fields (and pointers to them)")
+	}
+
+	if types.NewMethodSet(types.NewPointer(typ)).Lookup(nil, "UnmarshalText") != nil {
+		return "", fmt.Errorf("default is not supported on %s as it implements encoding.TextUnmarshaler", typ)
+	}
+
+	lit := field.defaultLit
+	basic := typ.Underlying().(*types.Basic)
+	typeName := types.TypeString(typ, g.qualifier)
+
+	// as struct2map parses defaults, an empty literal is the zero value of anything but a string
+	if lit == "" && basic.Info()&types.IsString == 0 {
+		if basic.Info()&types.IsBoolean != 0 {
+			return fmt.Sprintf("%s(false)", typeName), nil
+		}
+		return fmt.Sprintf("%s(0)", typeName), nil
+	}
+
+	bits := int(types.SizesFor("gc", "amd64").Sizeof(basic) * 8)
+	var expr string
+	var err error
+	switch info := basic.Info(); {
+	case info&types.IsBoolean != 0:
+		var parsed bool
+		parsed, err = strconv.ParseBool(lit)
+		expr = strconv.FormatBool(parsed)
+	case info&types.IsString != 0:
+		expr = strconv.Quote(lit)
+	case types.TypeString(typ, nil) == "time.Duration":
+		var parsed time.Duration
+		parsed, err = time.ParseDuration(lit)
+		expr = strconv.FormatInt(int64(parsed), 10)
+	case info&types.IsUnsigned != 0:
+		var parsed uint64
+		parsed, err = strconv.ParseUint(lit, 10, bits)
+		expr = strconv.FormatUint(parsed, 10)
+	case info&types.IsInteger != 0:
+		var parsed int64
+		parsed, err = strconv.ParseInt(lit, 10, bits)
+		expr = strconv.FormatInt(parsed, 10)
+	case info&types.IsFloat != 0:
+		var parsed float64
+		parsed, err = strconv.ParseFloat(lit, bits)
+		expr = strconv.FormatFloat(parsed, 'g', -1, bits)
+	default:
+		return "", fmt.Errorf("default is not supported on %s", typ)
+	}
+	if err != nil {
+		return "", fmt.Errorf("invalid default %q for %s: %w", lit, typ, err)
+	}
+
+	return fmt.Sprintf("%s(%s)", typeName, expr), nil
+}
+
+// the condition true when a basic field holds its zero value
+func zeroCheck(field genField) string {
+	info := field.typ.Underlying().(*types.Basic).Info()
+	switch {
+	case info&types.IsBoolean != 0:
+		return fmt.Sprintf("!v.%s", field.goName)
+	case info&types.IsString != 0:
+		return fmt.Sprintf("v.%s == \"\"", field.goName)
+	}
+
+	return fmt.Sprintf("v.%s == 0", field.goName)
+}
+
+// closes the nil check of a pointer field, storing nil unless the field is omitempty
+func (g *generator) printNilElse(field genField, key string) {
+	if field.omitEmpty {
+		g.printf("\n")
+		return
+	}
+	g.printf(" else {\nm[%s] = nil\n}\n", key)
+}
+
+func (g *generator) writeToMap(named *types.Named) {
+	typeName := named.Obj().Name()
+	g.printf("// ToMap converts v into a single, flat map exactly as struct2map.ConvertStruct does, without reflection\n")
+	g.printf("func (v %s) ToMap() map[string]any {\nm := make(map[string]any)\nv.%s(m, \"\")\nreturn m\n}\n\n", typeName, g.helper)
+}
+
+// a basic field FromMap can assign directly, reached through a chain of structures held by value
+type fromMapCase struct {
+	key    string
+	path   string
+	typ    types.Type
+	redact bool // the key holds the redaction mask and is skipped
+}
+
+func (g *generator) writeFromMap(named *types.Named) {
+	cases := g.fromMapCases(named, "", "tmp", map[*types.Named]bool{})
+
+	// keys produced by more than one field (ex: through ignoreparents) are left to Patch
+	keyCount := make(map[string]int)
+	for _, fmCase := range cases {
+		keyCount[fmCase.key]++
+	}
+
+	var direct []fromMapCase
+	var redacted []string
+	for _, fmCase := range cases {
+		if keyCount[fmCase.key] > 1 {
+			continue
+		}
+		if fmCase.redact {
+			redacted = append(redacted, strconv.Quote(fmCase.key))
+			continue
+		}
+		direct = append(direct, fmCase)
+	}
+
+	typeName := named.Obj().Name()
+	g.printf("// FromMap applies the keys of m (as ToMap produces them) to v; values holding exactly the field's type are\n")
+	g.printf("// assigned directly, after everything else is applied as struct2map.Patch applies it (as Patch applies a key\n")
+	g.printf("// before the keys below it). Like Patch, either every key is applied or v is left untouched. Redacted keys are\n")
+	g.printf("// skipped, so the mask ToMap stores in their place is never written back over the value.\n")
+	g.printf("func (v *%s) FromMap(m map[string]any) error {\ntmp := *v\nvar rest map[string]any\n", typeName)
+	g.printf("for key, val := range m {\nswitch key {\n")
+	for _, fmCase := range direct {
+		g.printf("case %q:\nif _, ok := val.(%s); ok {\ncontinue\n}\n", fmCase.key, types.TypeString(fmCase.typ, g.qualifier))
+	}
+	if redacted != nil {
+		g.printf("case %s:\ncontinue\n", strings.Join(redacted, ", "))
+	}
+	g.printf("}\nif rest == nil {\nrest = make(map[string]any)\n}\nrest[key] = val\n}\n\n")
+	g.printf("if rest != nil {\nif err := struct2map.Patch(&tmp, rest%s); err != nil {\nreturn err\n}\n}\n\n", g.optsArg())
+	if direct != nil {
+		g.printf("for key, val := range m {\nswitch key {\n")
+		for _, fmCase := range direct {
+			g.printf("case %q:\nif typed, ok := val.(%s); ok {\n%s = typed\n}\n", fmCase.key, types.TypeString(fmCase.typ, g.qualifier), fmCase.path)
+		}
+		g.printf("}\n}\n\n")
+	}
+	g.printf("*v = tmp\nreturn nil\n}\n\n")
+}
+
+// pointers are only followed for the redacted keys below them (with an empty path), so tmp never shares anything
+// FromMap assigns with v until it succeeds
+func (g *generator) fromMapCases(named *types.Named, prefix, path string, inProgress map[*types.Named]bool) []fromMapCase {
+	if inProgress[named] {
+		return nil
+	}
+	inProgress[named] = true
+	defer delete(inProgress, named)
+
+	var ret []fromMapCase
+	for _, field := range g.fields(named) {
+		if field.ignoreParents {
+			prefix = ""
+		}
+
+		fieldPath := ""
+		if path != "" {
+			fieldPath = fmt.Sprintf("%s.%s", path, field.goName)
+		}
+
+		switch {
+		case field.redact:
+			ret = append(ret, fromMapCase{key: prefix + field.key, redact: true})
+		case field.kind == kindBasic && fieldPath != "":
+			ret = append(ret, fromMapCase{key: prefix + field.key, path: fieldPath, typ: field.typ})
+		case field.kind == kindStruct:
+			ret = append(ret, g.fromMapCases(g.structOf(field.typ), prefix+field.key+".", fieldPath, inProgress)...)
+		case field.kind == kindPtrStruct:
+			ret = append(ret, g.fromMapCases(g.structOf(field.typ), prefix+field.key+".", "", inProgress)...)
+		}
+	}
+
+	return ret
+}
+
+func (g *generator) qualifier(pkg *types.Package) string {
+	if pkg == g.pkg {
+		return ""
+	}
+
+	g.imports[pkg.Path()] = pkg.Name()
+	return pkg.Name()
+}
+
+// the trailing options argument matching the case modifier, if any
+func (g *generator) optsArg() string {
+	if g.caseMod == nil {
+		return ""
+	}
+
+	return fmt.Sprintf(", struct2map.%s", g.caseMod.option)
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) source() ([]byte, error) {
+	g.imports[struct2mapImport] = "struct2map"
+
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by struct2map-gen; DO NOT EDIT.\n\npackage %s\n\nimport (\n", g.pkg.Name())
+	for _, path := range paths {
+		if path != struct2mapImport {
+			fmt.Fprintf(&src, "%q\n", path)
+		}
+	}
+	fmt.Fprintf(&src, "\nstruct2map %q\n)\n\n", struct2mapImport)
+	src.Write(g.buf.Bytes())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+
+	return formatted, nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// the checked in fixture files must be exactly what the generator produces today
+func Test_GenerateGolden(t *testing.T) {
+	fixturesDir := filepath.Join("internal", "fixtures")
+
+	testSet := []struct {
+		Name      string
+		OutFile   string
+		TypeNames []string
+		CaseName  string
+		ExpErr    bool
+		SkipTest  bool
+	}{
+		{
+			Name:      "no case modifier",
+			OutFile:   "config_struct2map.go",
+			TypeNames: []string{"Config", "Node"},
+		},
+		{
+			Name:      "snake case",
+			OutFile:   "snakeconfig_struct2map.go",
+			TypeNames: []string{"SnakeConfig"},
+			CaseName:  "snake",
+		},
+		{
+			Name:      "unknown type",
+			TypeNames: []string{"Missing"},
+			ExpErr:    true,
+		},
+		{
+			Name:      "not a structure",
+			TypeNames: []string{"Tags"},
+			ExpErr:    true,
+		},
+		{
+			Name:      "default that does not parse",
+			TypeNames: []string{"BadDefault"},
+			ExpErr:    true,
+		},
+		{
+			Name:      "default on an unsupported field",
+			TypeNames: []string{"UnsupportedDefault"},
+			ExpErr:    true,
+		},
+		{
+			Name:      "unknown case modifier",
+			TypeNames: []string{"Config"},
+			CaseName:  "kebab",
+			ExpErr:    true,
+		},
+	}
+
+	for _, curTest := range testSet {
+		t.Run(curTest.Name, func(t *testing.T) {
+			if curTest.SkipTest {
+				t.Skipf("skipped '%s' due to SkipTest being set", curTest.Name)
+			}
+
+			genSrc, err := generate(fixturesDir, curTest.OutFile, curTest.TypeNames, curTest.CaseName)
+			if curTest.ExpErr {
+				if err == nil {
+					t.Errorf("expected an error generating %v", curTest.TypeNames)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			golden, err := os.ReadFile(filepath.Join(fixturesDir, curTest.OutFile))
+			if err != nil {
+				t.Fatalf("reading golden file: %v", err)
+			}
+			if string(genSrc) != string(golden) {
+				t.Errorf("generated code differs from %s; run go generate in %s\n%s", curTest.OutFile, fixturesDir, genSrc)
+			}
+		})
+	}
+}
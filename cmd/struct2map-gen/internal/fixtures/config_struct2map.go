@@ -0,0 +1,365 @@
+// Code generated by struct2map-gen; DO NOT EDIT.
+
+package fixtures
+
+import (
+	"strconv"
+	"time"
+
+	struct2map "github.com/newodahs/struct2map/pkg"
+)
+
+func (v *Config) struct2mapInto(m map[string]any, prefix string) {
+	m[prefix+"name"] = v.Name
+	m[prefix+"debug"] = v.Debug
+	m[prefix+"level"] = v.Level
+	m[prefix+"ratio"] = v.Ratio
+	if v.Count != nil {
+		m[prefix+"count"] = *v.Count
+	} else {
+		m[prefix+"count"] = nil
+	}
+	if v.Limit != nil {
+		m[prefix+"limit"] = *v.Limit
+	}
+	for idx, item := range v.Tags {
+		m[prefix+"tags."+strconv.Itoa(idx)] = item
+	}
+	struct2map.ConvertValueInto(m, prefix+"pair", v.Pair, false)
+	v.Server.struct2mapInto(m, prefix+"server.")
+	if v.Backup != nil {
+		v.Backup.struct2mapInto(m, prefix+"backup.")
+	} else {
+		m[prefix+"backup"] = nil
+	}
+	if v.Spare != nil {
+		v.Spare.struct2mapInto(m, prefix+"spare.")
+	}
+	v.Auth.struct2mapInto(m, prefix+"auth.")
+	if v.Login != nil {
+		v.Login.struct2mapInto(m, prefix+"login.")
+	}
+	struct2map.ConvertValueInto(m, prefix+"servers", v.Servers, false)
+	struct2map.ConvertValueInto(m, prefix+"labels", v.Labels, false)
+	struct2map.ConvertValueInto(m, prefix+"nodes", v.Nodes, false)
+	struct2map.ConvertValueInto(m, prefix+"extra", &v.Extra, false)
+	struct2map.ConvertValueInto(m, prefix+"started", v.Started, false)
+	prefix = ""
+	v.Flat.struct2mapInto(m, prefix+"flat.")
+	m[prefix+"afterFlat"] = v.AfterFlat
+	v.Head.struct2mapInto(m, prefix+"head.")
+	m[prefix+"Untagged"] = v.Untagged
+	struct2map.ConvertValueInto(m, prefix+"inline", v.Inline, false)
+	v.Server2.struct2mapInto(m, prefix+"Server2.")
+	struct2map.ConvertValueInto(m, prefix+"ptrs", v.Ptrs, false)
+	if v.Retries == 0 {
+		m[prefix+"retries"] = int8(3)
+	} else {
+		m[prefix+"retries"] = v.Retries
+	}
+	if v.Region != nil {
+		m[prefix+"region"] = *v.Region
+	} else {
+		m[prefix+"region"] = string("us-east")
+	}
+	if v.Wait == 0 {
+		m[prefix+"wait"] = time.Duration(90000000000)
+	} else {
+		m[prefix+"wait"] = v.Wait
+	}
+	if v.Rate == 0 {
+		m[prefix+"rate"] = float32(0.1)
+	} else {
+		m[prefix+"rate"] = v.Rate
+	}
+	if v.Verbose == 0 {
+		m[prefix+"verbose"] = Level(2)
+	} else {
+		m[prefix+"verbose"] = v.Verbose
+	}
+	if !v.Quiet {
+		m[prefix+"quiet"] = bool(true)
+	} else {
+		m[prefix+"quiet"] = v.Quiet
+	}
+}
+
+func (v *Node) struct2mapInto(m map[string]any, prefix string) {
+	m[prefix+"name"] = v.Name
+	if v.Next != nil {
+		v.Next.struct2mapInto(m, prefix+"next.")
+	}
+}
+
+func (v *Server) struct2mapInto(m map[string]any, prefix string) {
+	m[prefix+"host"] = v.Host
+	m[prefix+"port"] = v.Port
+	m[prefix+"Timeout"] = v.Timeout
+}
+
+func (v *Auth) struct2mapInto(m map[string]any, prefix string) {
+	m[prefix+"user"] = v.User
+	m[prefix+"password"] = struct2map.DEFAULT_REDACT_MASK
+}
+
+// ToMap converts v into a single, flat map exactly as struct2map.ConvertStruct does, without reflection
+func (v Config) ToMap() map[string]any {
+	m := make(map[string]any)
+	v.struct2mapInto(m, "")
+	return m
+}
+
+// FromMap applies the keys of m (as ToMap produces them) to v; values holding exactly the field's type are
+// assigned directly, after everything else is applied as struct2map.Patch applies it (as Patch applies a key
+// before the keys below it). Like Patch, either every key is applied or v is left untouched. Redacted keys are
+// skipped, so the mask ToMap stores in their place is never written back over the value.
+func (v *Config) FromMap(m map[string]any) error {
+	tmp := *v
+	var rest map[string]any
+	for key, val := range m {
+		switch key {
+		case "name":
+			if _, ok := val.(string); ok {
+				continue
+			}
+		case "debug":
+			if _, ok := val.(bool); ok {
+				continue
+			}
+		case "level":
+			if _, ok := val.(Level); ok {
+				continue
+			}
+		case "ratio":
+			if _, ok := val.(float32); ok {
+				continue
+			}
+		case "server.host":
+			if _, ok := val.(string); ok {
+				continue
+			}
+		case "server.port":
+			if _, ok := val.(int); ok {
+				continue
+			}
+		case "server.Timeout":
+			if _, ok := val.(time.Duration); ok {
+				continue
+			}
+		case "auth.user":
+			if _, ok := val.(string); ok {
+				continue
+			}
+		case "flat.host":
+			if _, ok := val.(string); ok {
+				continue
+			}
+		case "flat.port":
+			if _, ok := val.(int); ok {
+				continue
+			}
+		case "flat.Timeout":
+			if _, ok := val.(time.Duration); ok {
+				continue
+			}
+		case "afterFlat":
+			if _, ok := val.(string); ok {
+				continue
+			}
+		case "head.name":
+			if _, ok := val.(string); ok {
+				continue
+			}
+		case "Untagged":
+			if _, ok := val.(string); ok {
+				continue
+			}
+		case "Server2.host":
+			if _, ok := val.(string); ok {
+				continue
+			}
+		case "Server2.port":
+			if _, ok := val.(int); ok {
+				continue
+			}
+		case "Server2.Timeout":
+			if _, ok := val.(time.Duration); ok {
+				continue
+			}
+		case "retries":
+			if _, ok := val.(int8); ok {
+				continue
+			}
+		case "wait":
+			if _, ok := val.(time.Duration); ok {
+				continue
+			}
+		case "rate":
+			if _, ok := val.(float32); ok {
+				continue
+			}
+		case "verbose":
+			if _, ok := val.(Level); ok {
+				continue
+			}
+		case "quiet":
+			if _, ok := val.(bool); ok {
+				continue
+			}
+		case "auth.password", "login.password":
+			continue
+		}
+		if rest == nil {
+			rest = make(map[string]any)
+		}
+		rest[key] = val
+	}
+
+	if rest != nil {
+		if err := struct2map.Patch(&tmp, rest); err != nil {
+			return err
+		}
+	}
+
+	for key, val := range m {
+		switch key {
+		case "name":
+			if typed, ok := val.(string); ok {
+				tmp.Name = typed
+			}
+		case "debug":
+			if typed, ok := val.(bool); ok {
+				tmp.Debug = typed
+			}
+		case "level":
+			if typed, ok := val.(Level); ok {
+				tmp.Level = typed
+			}
+		case "ratio":
+			if typed, ok := val.(float32); ok {
+				tmp.Ratio = typed
+			}
+		case "server.host":
+			if typed, ok := val.(string); ok {
+				tmp.Server.Host = typed
+			}
+		case "server.port":
+			if typed, ok := val.(int); ok {
+				tmp.Server.Port = typed
+			}
+		case "server.Timeout":
+			if typed, ok := val.(time.Duration); ok {
+				tmp.Server.Timeout = typed
+			}
+		case "auth.user":
+			if typed, ok := val.(string); ok {
+				tmp.Auth.User = typed
+			}
+		case "flat.host":
+			if typed, ok := val.(string); ok {
+				tmp.Flat.Host = typed
+			}
+		case "flat.port":
+			if typed, ok := val.(int); ok {
+				tmp.Flat.Port = typed
+			}
+		case "flat.Timeout":
+			if typed, ok := val.(time.Duration); ok {
+				tmp.Flat.Timeout = typed
+			}
+		case "afterFlat":
+			if typed, ok := val.(string); ok {
+				tmp.AfterFlat = typed
+			}
+		case "head.name":
+			if typed, ok := val.(string); ok {
+				tmp.Head.Name = typed
+			}
+		case "Untagged":
+			if typed, ok := val.(string); ok {
+				tmp.Untagged = typed
+			}
+		case "Server2.host":
+			if typed, ok := val.(string); ok {
+				tmp.Server2.Host = typed
+			}
+		case "Server2.port":
+			if typed, ok := val.(int); ok {
+				tmp.Server2.Port = typed
+			}
+		case "Server2.Timeout":
+			if typed, ok := val.(time.Duration); ok {
+				tmp.Server2.Timeout = typed
+			}
+		case "retries":
+			if typed, ok := val.(int8); ok {
+				tmp.Retries = typed
+			}
+		case "wait":
+			if typed, ok := val.(time.Duration); ok {
+				tmp.Wait = typed
+			}
+		case "rate":
+			if typed, ok := val.(float32); ok {
+				tmp.Rate = typed
+			}
+		case "verbose":
+			if typed, ok := val.(Level); ok {
+				tmp.Verbose = typed
+			}
+		case "quiet":
+			if typed, ok := val.(bool); ok {
+				tmp.Quiet = typed
+			}
+		}
+	}
+
+	*v = tmp
+	return nil
+}
+
+// ToMap converts v into a single, flat map exactly as struct2map.ConvertStruct does, without reflection
+func (v Node) ToMap() map[string]any {
+	m := make(map[string]any)
+	v.struct2mapInto(m, "")
+	return m
+}
+
+// FromMap applies the keys of m (as ToMap produces them) to v; values holding exactly the field's type are
+// assigned directly, after everything else is applied as struct2map.Patch applies it (as Patch applies a key
+// before the keys below it). Like Patch, either every key is applied or v is left untouched. Redacted keys are
+// skipped, so the mask ToMap stores in their place is never written back over the value.
+func (v *Node) FromMap(m map[string]any) error {
+	tmp := *v
+	var rest map[string]any
+	for key, val := range m {
+		switch key {
+		case "name":
+			if _, ok := val.(string); ok {
+				continue
+			}
+		}
+		if rest == nil {
+			rest = make(map[string]any)
+		}
+		rest[key] = val
+	}
+
+	if rest != nil {
+		if err := struct2map.Patch(&tmp, rest); err != nil {
+			return err
+		}
+	}
+
+	for key, val := range m {
+		switch key {
+		case "name":
+			if typed, ok := val.(string); ok {
+				tmp.Name = typed
+			}
+		}
+	}
+
+	*v = tmp
+	return nil
+}
@@ -0,0 +1,82 @@
+// Package fixtures holds the structure types the struct2map-gen tests generate code for; the generated files are
+// checked in and kept current by the tests.
+package fixtures
+
+import (
+	"time"
+)
+
+//go:generate go run github.com/newodahs/struct2map/cmd/struct2map-gen -type Config,Node
+//go:generate go run github.com/newodahs/struct2map/cmd/struct2map-gen -type SnakeConfig -case snake
+
+type Level int
+
+type Tags []string
+
+type Server struct {
+	Host    string `struct2map:"host"`
+	Port    int    `struct2map:"port"`
+	Timeout time.Duration
+}
+
+type Auth struct {
+	User     string `struct2map:"user"`
+	Password string `struct2map:"password,redact"`
+}
+
+type Node struct {
+	Name string `struct2map:"name"`
+	Next *Node  `struct2map:"next,omitempty"`
+}
+
+type Config struct {
+	Name       string            `struct2map:"name"`
+	Debug      bool              `struct2map:"debug"`
+	Level      Level             `struct2map:"level"`
+	Ratio      float32           `struct2map:"ratio"`
+	Count      *uint64           `struct2map:"count"`
+	Limit      *int              `struct2map:"limit,omitempty"`
+	Tags       Tags              `struct2map:"tags"`
+	Pair       [2]bool           `struct2map:"pair"`
+	Server     Server            `struct2map:"server"`
+	Backup     *Server           `struct2map:"backup"`
+	Spare      *Server           `struct2map:"spare,omitempty"`
+	Auth       Auth              `struct2map:"auth"`
+	Login      *Auth             `struct2map:"login,omitempty"`
+	Servers    map[string]Server `struct2map:"servers"`
+	Labels     map[string]string `struct2map:"labels"`
+	Nodes      []Node            `struct2map:"nodes"`
+	Extra      any               `struct2map:"extra"`
+	Started    time.Time         `struct2map:"started"`
+	Flat       Server            `struct2map:"flat,ignoreparents"`
+	AfterFlat  string            `struct2map:"afterFlat"`
+	Head       Node              `struct2map:"head"`
+	Untagged   string
+	Skipped    string `struct2map:"-"`
+	unexported string
+	Inline     struct{ A, B int } `struct2map:"inline"`
+	Server2    Server
+	Ptrs       []*int        `struct2map:"ptrs"`
+	Retries    int8          `struct2map:"retries,default=3"`
+	Region     *string       `struct2map:"region,default=us-east"`
+	Wait       time.Duration `struct2map:"wait,default=1m30s"`
+	Rate       float32       `struct2map:"rate,default=0.1"`
+	Verbose    Level         `struct2map:"verbose,default=2"`
+	Quiet      bool          `struct2map:"quiet,default=true"`
+}
+
+type SnakeConfig struct {
+	ServerName  string `struct2map:"ignoredByCase"`
+	MaxConns    int
+	MainServer  Server
+	ExtraLabels map[string]int
+}
+
+// only used to check struct2map-gen rejects them
+type BadDefault struct {
+	Port int `struct2map:"port,default=eighty"`
+}
+
+type UnsupportedDefault struct {
+	Tags []string `struct2map:"tags,default=a"`
+}
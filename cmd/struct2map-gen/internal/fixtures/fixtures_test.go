@@ -0,0 +1,136 @@
+package fixtures
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	struct2map "github.com/newodahs/struct2map/pkg"
+)
+
+func Test_ToMapParity(t *testing.T) {
+	count := uint64(7)
+	limit := 3
+	one := 1
+	region := "eu-west"
+
+	testSet := []struct {
+		Name       string
+		TestStruct Config
+		SkipTest   bool
+	}{
+		{
+			Name: "zero value",
+		},
+		{
+			Name: "everything set",
+			TestStruct: Config{
+				Name:      "cfg",
+				Debug:     true,
+				Level:     2,
+				Ratio:     0.5,
+				Count:     &count,
+				Limit:     &limit,
+				Tags:      Tags{"a", "b"},
+				Pair:      [2]bool{true, false},
+				Server:    Server{Host: "h", Port: 80, Timeout: time.Second},
+				Backup:    &Server{Host: "b"},
+				Spare:     &Server{Port: 1},
+				Auth:      Auth{User: "u", Password: "p"},
+				Login:     &Auth{User: "l", Password: "lp"},
+				Servers:   map[string]Server{"primary": {Host: "p"}},
+				Labels:    map[string]string{"team": "core"},
+				Nodes:     []Node{{Name: "n0", Next: &Node{Name: "n1"}}},
+				Extra:     map[string]any{"deep": []int{1, 2}},
+				Started:   time.Unix(0, 0),
+				Flat:      Server{Host: "f"},
+				AfterFlat: "after",
+				Head:      Node{Name: "head", Next: &Node{Name: "tail"}},
+				Untagged:  "u",
+				Skipped:   "s",
+				Inline:    struct{ A, B int }{A: 1},
+				Server2:   Server{Port: 2},
+				Ptrs:      []*int{&one, nil},
+				Retries:   -1,
+				Region:    &region,
+				Wait:      time.Second,
+			},
+		},
+	}
+
+	for _, curTest := range testSet {
+		t.Run(curTest.Name, func(t *testing.T) {
+			if curTest.SkipTest {
+				t.Skipf("skipped '%s' due to SkipTest being set", curTest.Name)
+			}
+
+			genMap := curTest.TestStruct.ToMap()
+			expectedMap := struct2map.ConvertStruct(curTest.TestStruct)
+			if !reflect.DeepEqual(genMap, expectedMap) {
+				t.Errorf("generated map not the same as ConvertStruct's map\nHave: %v\nWant: %v", genMap, expectedMap)
+			}
+		})
+	}
+
+	snake := SnakeConfig{ServerName: "s", MaxConns: 3, MainServer: Server{Host: "h"}, ExtraLabels: map[string]int{"a": 1}}
+	genMap := snake.ToMap()
+	expectedMap := struct2map.ConvertStruct(snake, struct2map.STRUCT_CONVERT_MAPKEY_SNAKE)
+	if !reflect.DeepEqual(genMap, expectedMap) {
+		t.Errorf("generated map not the same as ConvertStruct's map\nHave: %v\nWant: %v", genMap, expectedMap)
+	}
+}
+
+func Test_FromMap(t *testing.T) {
+	count := uint64(7)
+	original := Config{
+		Name:   "cfg",
+		Level:  2,
+		Count:  &count,
+		Tags:   Tags{"a", "b"},
+		Server: Server{Host: "h", Port: 80, Timeout: time.Second},
+		Backup: &Server{Host: "b"},
+		Auth:   Auth{User: "u", Password: "secret"},
+		Login:  &Auth{User: "l", Password: "hunter2"},
+		Labels: map[string]string{"team": "core"},
+		Head:   Node{Name: "head", Next: &Node{Name: "tail"}},
+	}
+
+	// redacted keys are skipped, so the passwords already held are never replaced by the mask
+	loaded := Config{Auth: Auth{Password: "secret"}, Login: &Auth{Password: "hunter2"}}
+	if err := loaded.FromMap(original.ToMap()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loaded.Auth.Password != "secret" || loaded.Login.Password != "hunter2" {
+		t.Errorf("redacted passwords not kept: %q, %q", loaded.Auth.Password, loaded.Login.Password)
+	}
+
+	// the ignoreparents key rules and zero fields with defaults are the only things that do not round trip
+	region := "us-east"
+	original.Retries, original.Region, original.Wait, original.Rate, original.Verbose, original.Quiet = 3, &region, 90*time.Second, 0.1, 2, true
+	if !reflect.DeepEqual(loaded, original) {
+		t.Errorf("loaded value not the same as the original value\nHave: %+v\nWant: %+v", loaded, original)
+	}
+
+	// values of other types are converted as Patch converts them and failures leave the value untouched
+	if err := loaded.FromMap(map[string]any{"server.port": "8080", "level": int64(3)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loaded.Server.Port != 8080 || loaded.Level != 3 {
+		t.Errorf("converted values not applied: %+v", loaded)
+	}
+
+	// as with Patch, a key is applied before the keys below it
+	if err := loaded.FromMap(map[string]any{"server": Server{Host: "s", Port: 1}, "server.port": 5}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if loaded.Server != (Server{Host: "s", Port: 5}) {
+		t.Errorf("keys not applied parents first: %+v", loaded.Server)
+	}
+
+	if err := loaded.FromMap(map[string]any{"name": "changed", "server.port": "eighty"}); err == nil {
+		t.Errorf("expected an error for a bad value")
+	}
+	if loaded.Name != "cfg" {
+		t.Errorf("value changed despite the error: %+v", loaded)
+	}
+}
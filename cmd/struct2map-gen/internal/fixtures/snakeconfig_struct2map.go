@@ -0,0 +1,100 @@
+// Code generated by struct2map-gen; DO NOT EDIT.
+
+package fixtures
+
+import (
+	"time"
+
+	struct2map "github.com/newodahs/struct2map/pkg"
+)
+
+func (v *SnakeConfig) struct2mapSnakeInto(m map[string]any, prefix string) {
+	m[prefix+"server_name"] = v.ServerName
+	m[prefix+"max_conns"] = v.MaxConns
+	v.MainServer.struct2mapSnakeInto(m, prefix+"main_server.")
+	struct2map.ConvertValueInto(m, prefix+"extra_labels", v.ExtraLabels, false, struct2map.STRUCT_CONVERT_MAPKEY_SNAKE)
+}
+
+func (v *Server) struct2mapSnakeInto(m map[string]any, prefix string) {
+	m[prefix+"host"] = v.Host
+	m[prefix+"port"] = v.Port
+	m[prefix+"timeout"] = v.Timeout
+}
+
+// ToMap converts v into a single, flat map exactly as struct2map.ConvertStruct does, without reflection
+func (v SnakeConfig) ToMap() map[string]any {
+	m := make(map[string]any)
+	v.struct2mapSnakeInto(m, "")
+	return m
+}
+
+// FromMap applies the keys of m (as ToMap produces them) to v; values holding exactly the field's type are
+// assigned directly, after everything else is applied as struct2map.Patch applies it (as Patch applies a key
+// before the keys below it). Like Patch, either every key is applied or v is left untouched. Redacted keys are
+// skipped, so the mask ToMap stores in their place is never written back over the value.
+func (v *SnakeConfig) FromMap(m map[string]any) error {
+	tmp := *v
+	var rest map[string]any
+	for key, val := range m {
+		switch key {
+		case "server_name":
+			if _, ok := val.(string); ok {
+				continue
+			}
+		case "max_conns":
+			if _, ok := val.(int); ok {
+				continue
+			}
+		case "main_server.host":
+			if _, ok := val.(string); ok {
+				continue
+			}
+		case "main_server.port":
+			if _, ok := val.(int); ok {
+				continue
+			}
+		case "main_server.timeout":
+			if _, ok := val.(time.Duration); ok {
+				continue
+			}
+		}
+		if rest == nil {
+			rest = make(map[string]any)
+		}
+		rest[key] = val
+	}
+
+	if rest != nil {
+		if err := struct2map.Patch(&tmp, rest, struct2map.STRUCT_CONVERT_MAPKEY_SNAKE); err != nil {
+			return err
+		}
+	}
+
+	for key, val := range m {
+		switch key {
+		case "server_name":
+			if typed, ok := val.(string); ok {
+				tmp.ServerName = typed
+			}
+		case "max_conns":
+			if typed, ok := val.(int); ok {
+				tmp.MaxConns = typed
+			}
+		case "main_server.host":
+			if typed, ok := val.(string); ok {
+				tmp.MainServer.Host = typed
+			}
+		case "main_server.port":
+			if typed, ok := val.(int); ok {
+				tmp.MainServer.Port = typed
+			}
+		case "main_server.timeout":
+			if typed, ok := val.(time.Duration); ok {
+				tmp.MainServer.Timeout = typed
+			}
+		}
+	}
+
+	*v = tmp
+	return nil
+}
@@ -0,0 +1,62 @@
+// Command struct2map-gen generates reflection free ToMap and FromMap methods for structure types, producing the same
+// keys (tag options, ignored parents, case modifiers and all) as struct2map.ConvertStruct.
+//
+// Usage (typically from a go:generate line in the package holding the types):
+//
+//	//go:generate go run github.com/newodahs/struct2map/cmd/struct2map-gen -type Config,Server [-case snake] [-output file.go] [dir]
+//
+// For every type named with -type the following methods are generated:
+//
+//	func (v T) ToMap() map[string]any       // same output as struct2map.ConvertStruct(v)
+//	func (v *T) FromMap(m map[string]any) error // the reverse, as struct2map.Patch applies keys
+//
+// along with an unexported helper for every structure type of the same package reachable from them. Field types
+// that are not converted directly (ex: maps, interfaces, structures from other packages) fall back to the reflective
+// conversion for that field alone.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma separated list of the structure types to generate methods for (required)")
+	caseName := flag.String("case", "", "case modifier applied to the field names, as the STRUCT_CONVERT_MAPKEY_* options do (lower, upper, camel, lowercamel or snake)")
+	output := flag.String("output", "", "output file name (default: <first type>_struct2map.go in the package directory)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: struct2map-gen -type T[,T...] [-case modifier] [-output file] [dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *typeNames == "" || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	if flag.NArg() == 1 {
+		dir = flag.Arg(0)
+	}
+
+	types := strings.Split(*typeNames, ",")
+	outFile := *output
+	if outFile == "" {
+		outFile = filepath.Join(dir, fmt.Sprintf("%s_struct2map.go", strings.ToLower(types[0])))
+	}
+
+	src, err := generate(dir, filepath.Base(outFile), types, *caseName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "struct2map-gen: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := os.WriteFile(outFile, src, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "struct2map-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -4,24 +4,64 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"time"
 )
 
 const (
 	STRUCT_MAP_PRIMARY_TAGNAME   = "struct2map"
+	STRUCT_MAP_DESC_TAGNAME      = "desc"          // free text describing the field (ex: usage text for command-line flags)
+	STRUCT_MAP_ENUM_TAGNAME      = "enum"          // the values allowed for the field, separated by |
+	STRUCT_MAP_MIN_TAGNAME       = "min"           // the lowest number (or shortest string) allowed for the field
+	STRUCT_MAP_MAX_TAGNAME       = "max"           // the highest number (or longest string) allowed for the field
 	STRUCT_MAP_TAG_OMIT          = "omitempty"     // for nil-able values only; if nil, don't add to map
 	STRUCT_MAP_TAG_IGNORE_PARENT = "ignoreparents" // don't use any of the parent names above this item; parents still honored for items contained within this item
+	STRUCT_MAP_TAG_REQUIRED      = "required"      // reverse conversions (ex: loading from the environment) fail if nothing is found for this item
+	STRUCT_MAP_TAG_LABEL         = "label"         // metrics exports use this item as a label on every metric rather than as a metric of its own
+	STRUCT_MAP_TAG_REDACT        = "redact"        // the value of this item (and anything contained within it) is replaced as the redaction options direct
+	STRUCT_MAP_TAG_GROUPS        = "groups"        // as groups=a|b; this item (and anything contained within it) is only output when one of these groups is selected
+	STRUCT_MAP_TAG_DEFAULT       = "default"       // as default=8080; the value used in place of a zero item when converting and for a missing item when loading
 )
 
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// Controls how values are turned into strings; the zero value formats every value so that it parses back to the same
+// value (floats in their shortest exact form, times as time.RFC3339Nano, durations as time.Duration.String)
+type StringFormat struct {
+	FloatFormat byte   // the strconv.FormatFloat format ('g', 'e', 'f', ...); 'g' if unset. Always the shortest exact precision
+	TimeLayout  string // the time.Time.Format layout; time.RFC3339Nano if unset
+	TrueString  string // the string for true; "true" if unset
+	FalseString string // the string for false; "false" if unset
+	NilString   string // the string for nil values (nil pointers included)
+}
+
 func ConvertAnyToString(val any) string {
+	return ConvertAnyToStringFormat(val, StringFormat{})
+}
+
+// same as ConvertAnyToString but formats as format directs
+func ConvertAnyToStringFormat(val any, format StringFormat) string {
 	if val == nil {
-		return ""
+		return format.NilString
 	}
 
-	valOf := reflect.ValueOf(val)
+	return ConvertValueToStringFormat(reflect.ValueOf(val), format)
+}
+
+// same as ConvertAnyToString but works directly on the reflect.Value, including values that cannot be
+// turned back into an interface (ex: read through unexported structure fields)
+func ConvertValueToString(valOf reflect.Value) string {
+	return ConvertValueToStringFormat(valOf, StringFormat{})
+}
+
+// same as ConvertValueToString but formats as format directs
+func ConvertValueToStringFormat(valOf reflect.Value, format StringFormat) string {
 	for {
 		if valOf.Kind() == reflect.Pointer {
-			if reflect.Value(valOf).IsNil() {
-				return ""
+			if valOf.IsNil() {
+				return format.NilString
 			}
 			valOf = valOf.Elem()
 			continue
@@ -29,24 +69,55 @@ func ConvertAnyToString(val any) string {
 		break
 	}
 
+	if !valOf.IsValid() {
+		return format.NilString
+	}
+
+	floatFmt := format.FloatFormat
+	if floatFmt == 0 {
+		floatFmt = 'g'
+	}
+
+	switch valOf.Type() {
+	case timeType:
+		layout := format.TimeLayout
+		if layout == "" {
+			layout = time.RFC3339Nano
+		}
+		if valOf.CanInterface() {
+			return valOf.Interface().(time.Time).Format(layout)
+		}
+	case durationType:
+		return time.Duration(valOf.Int()).String()
+	}
+
 	switch valOf.Kind() {
 	case reflect.String:
 		return valOf.String()
 	case reflect.Complex64:
-		return strconv.FormatComplex(valOf.Complex(), 'g', -1, 64)
+		return strconv.FormatComplex(valOf.Complex(), floatFmt, -1, 64)
 	case reflect.Complex128:
-		return strconv.FormatComplex(valOf.Complex(), 'g', -1, 128)
+		return strconv.FormatComplex(valOf.Complex(), floatFmt, -1, 128)
 	case reflect.Float32:
-		return strconv.FormatFloat(valOf.Float(), 'g', -1, 32)
+		return strconv.FormatFloat(valOf.Float(), floatFmt, -1, 32)
 	case reflect.Float64:
-		return strconv.FormatFloat(valOf.Float(), 'g', -1, 64)
+		return strconv.FormatFloat(valOf.Float(), floatFmt, -1, 64)
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		return strconv.FormatInt(valOf.Int(), 10)
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		return strconv.FormatUint(valOf.Uint(), 10)
 	case reflect.Bool:
-		return strconv.FormatBool(valOf.Bool())
+		if valOf.Bool() {
+			if format.TrueString != "" {
+				return format.TrueString
+			}
+			return "true"
+		}
+		if format.FalseString != "" {
+			return format.FalseString
+		}
+		return "false"
 	}
 
-	return fmt.Sprintf("%v", val) // we don't support a proper conversion but let's return /something/ and hope for the best...
+	return fmt.Sprintf("%v", valOf) // we don't support a proper conversion but let's return /something/ and hope for the best...
 }
@@ -0,0 +1,556 @@
+package struct2map
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	ErrUnknownKey   = errors.New("key does not match any field")
+	ErrTypeMismatch = errors.New("value cannot be converted to the field type")
+	ErrOutOfRange   = errors.New("value is out of range for the field type")
+	ErrBadIndex     = errors.New("malformed or out of range slice index")
+)
+
+// Ties an error to the flattened key (or externally derived name, like an environment variable) that caused it
+type KeyError struct {
+	Key string
+	Err error
+}
+
+func (e *KeyError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Key, e.Err)
+}
+
+func (e *KeyError) Unwrap() error {
+	return e.Err
+}
+
+// upper bound on the slice index we are willing to grow a slice to when assigning; keeps hostile keys
+// (ex: Tags.99999999999) from allocating unreasonable amounts of memory
+const defaultMaxSliceIndex = 10000
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Returns a copy of cur with the value found by following the key segments (segs) replaced by val;
+// cur itself (and anything it references) is never modified so a failed assignment leaves no trace.
+//
+// isRoot signals that cur is the top level value, the only place ignoreparents fields can be addressed from
+func assignPath(cfg *convertConfig, cur reflect.Value, segs []string, val any, isRoot bool) (reflect.Value, error) {
+	curType := cur.Type()
+
+	if len(segs) == 0 {
+		return convertValue(curType, val)
+	}
+
+	switch curType.Kind() {
+	case reflect.Pointer:
+		elem := reflect.Zero(curType.Elem())
+		if !cur.IsNil() {
+			elem = cur.Elem()
+		}
+
+		newElem, err := assignPath(cfg, elem, segs, val, isRoot)
+		if err != nil {
+			return cur, err
+		}
+
+		ret := reflect.New(curType.Elem())
+		ret.Elem().Set(newElem)
+		return ret, nil
+	case reflect.Interface:
+		if cur.IsNil() {
+			if curType.NumMethod() != 0 {
+				return cur, fmt.Errorf("%w: cannot descend into nil %s", ErrTypeMismatch, curType)
+			}
+			cur = reflect.ValueOf(map[string]any{})
+		} else {
+			cur = cur.Elem()
+		}
+
+		newVal, err := assignPath(cfg, cur, segs, val, isRoot)
+		if err != nil {
+			return cur, err
+		}
+
+		ret := reflect.New(curType).Elem()
+		ret.Set(newVal)
+		return ret, nil
+	case reflect.Struct:
+		fieldPath := findField(cfg, curType, segs[0], isRoot)
+		if fieldPath == nil {
+			return cur, ErrUnknownKey
+		}
+
+		return assignStructField(cfg, cur, fieldPath, segs[1:], val)
+	case reflect.Map:
+		mapKey, err := parseMapKey(curType.Key(), segs[0])
+		if err != nil {
+			return cur, err
+		}
+
+		ret := reflect.MakeMapWithSize(curType, cur.Len())
+		mapItr := cur.MapRange()
+		for mapItr.Next() {
+			ret.SetMapIndex(mapItr.Key(), mapItr.Value())
+		}
+
+		if len(segs) == 1 && val == PATCH_DELETE {
+			ret.SetMapIndex(mapKey, reflect.Value{})
+			return ret, nil
+		}
+
+		existing := ret.MapIndex(mapKey)
+		if !existing.IsValid() {
+			existing = reflect.Zero(curType.Elem())
+		}
+
+		newVal, err := assignPath(cfg, existing, segs[1:], val, false)
+		if err != nil {
+			return cur, err
+		}
+
+		ret.SetMapIndex(mapKey, newVal)
+		return ret, nil
+	case reflect.Slice, reflect.Array:
+		idx, ok := parseSliceIndex(segs[0])
+		if !ok || idx > cfg.sliceIndexLimit() {
+			return cur, fmt.Errorf("%w: %q", ErrBadIndex, segs[0])
+		}
+
+		var ret reflect.Value
+		if curType.Kind() == reflect.Array {
+			if idx >= cur.Len() {
+				return cur, fmt.Errorf("%w: %d (array length %d)", ErrBadIndex, idx, cur.Len())
+			}
+			ret = reflect.New(curType).Elem()
+			ret.Set(cur)
+		} else {
+			if len(segs) == 1 && val == PATCH_DELETE {
+				if idx >= cur.Len() {
+					return cur, nil
+				}
+				ret = reflect.MakeSlice(curType, idx, idx)
+				reflect.Copy(ret, cur)
+				return ret, nil
+			}
+
+			ret = reflect.MakeSlice(curType, max(cur.Len(), idx+1), max(cur.Len(), idx+1))
+			reflect.Copy(ret, cur)
+		}
+
+		newVal, err := assignPath(cfg, ret.Index(idx), segs[1:], val, false)
+		if err != nil {
+			return cur, err
+		}
+
+		ret.Index(idx).Set(newVal)
+		return ret, nil
+	}
+
+	return cur, fmt.Errorf("%w: %s has no nested keys", ErrUnknownKey, curType)
+}
+
+// walks the field index path (through any intermediate structure pointers) of a copy of cur and assigns
+// the remaining key segments to the field it ends on
+func assignStructField(cfg *convertConfig, cur reflect.Value, fieldPath []int, segs []string, val any) (reflect.Value, error) {
+	ret := reflect.New(cur.Type()).Elem()
+	ret.Set(cur)
+
+	field := ret.Field(fieldPath[0])
+	var newVal reflect.Value
+	var err error
+	if len(fieldPath) == 1 {
+		newVal, err = assignPath(cfg, field, segs, val, false)
+	} else {
+		newVal, err = assignStructPointerField(cfg, field, fieldPath[1:], segs, val)
+	}
+	if err != nil {
+		return cur, err
+	}
+
+	field.Set(newVal)
+	return ret, nil
+}
+
+func assignStructPointerField(cfg *convertConfig, cur reflect.Value, fieldPath []int, segs []string, val any) (reflect.Value, error) {
+	if cur.Kind() != reflect.Pointer {
+		return assignStructField(cfg, cur, fieldPath, segs, val)
+	}
+
+	elem := reflect.Zero(cur.Type().Elem())
+	if !cur.IsNil() {
+		elem = cur.Elem()
+	}
+
+	newElem, err := assignStructPointerField(cfg, elem, fieldPath, segs, val)
+	if err != nil {
+		return cur, err
+	}
+
+	ret := reflect.New(cur.Type().Elem())
+	ret.Elem().Set(newElem)
+	return ret, nil
+}
+
+// Finds the field of structType that produces the key segment name; as in structToMap, ignoring parents drops the
+// prefix of a field and every field after it, so those fields are only found from the root, which also searches
+// nested structures for them.
+//
+// Returns: the field index path to the field (more than one index only for fields nested below the root) or nil if not found
+func findField(cfg *convertConfig, structType reflect.Type, name string, isRoot bool) []int {
+	ignoringParents := false
+	for pos := 0; pos < structType.NumField(); pos++ {
+		tag := parseFieldTag(cfg, structType.Field(pos))
+		if tag.skip || tag.unexported {
+			continue
+		}
+
+		if tag.ignoreParents {
+			ignoringParents = true
+		}
+		if ignoringParents && !isRoot {
+			break
+		}
+
+		if fieldKeyName(cfg, tag) == name {
+			return []int{pos}
+		}
+	}
+
+	if isRoot {
+		return findIgnoreParentsField(cfg, structType, name, map[reflect.Type]bool{})
+	}
+
+	return nil
+}
+
+func findIgnoreParentsField(cfg *convertConfig, structType reflect.Type, name string, seen map[reflect.Type]bool) []int {
+	if seen[structType] {
+		return nil
+	}
+	seen[structType] = true
+
+	for pos := 0; pos < structType.NumField(); pos++ {
+		field := structType.Field(pos)
+		tag := parseFieldTag(cfg, field)
+		if tag.skip || tag.unexported {
+			continue
+		}
+
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() != reflect.Struct {
+			continue
+		}
+
+		ignoringParents := false
+		for nestedPos := 0; nestedPos < fieldType.NumField(); nestedPos++ {
+			nestedTag := parseFieldTag(cfg, fieldType.Field(nestedPos))
+			if nestedTag.skip || nestedTag.unexported {
+				continue
+			}
+
+			if nestedTag.ignoreParents {
+				ignoringParents = true
+			}
+			if ignoringParents && fieldKeyName(cfg, nestedTag) == name {
+				return []int{pos, nestedPos}
+			}
+		}
+
+		if nestedPath := findIgnoreParentsField(cfg, fieldType, name, seen); nestedPath != nil {
+			return append([]int{pos}, nestedPath...)
+		}
+	}
+
+	return nil
+}
+
+// the key segment a field is output as, after any name modifier is applied and unexported marker is added
+func fieldKeyName(cfg *convertConfig, tag fieldTag) string {
+	name := tag.name
+	if cfg.nameModFunc != nil {
+		name = cfg.nameModFunc(name)
+	}
+
+	if tag.unexported {
+		return cfg.unexportedMarker + name
+	}
+
+	return name
+}
+
+// reverses the map key to string conversion done when flattening
+func parseMapKey(keyType reflect.Type, seg string) (reflect.Value, error) {
+	if keyType.Kind() == reflect.Pointer {
+		if seg == fmt.Sprintf("[%s]", DEFAULT_SUBKEY_STRING) {
+			return reflect.Zero(keyType), nil
+		}
+
+		elem, err := parseMapKey(keyType.Elem(), seg)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		ret := reflect.New(keyType.Elem())
+		ret.Elem().Set(elem)
+		return ret, nil
+	}
+
+	return convertValue(keyType, seg)
+}
+
+// Converts val to a value of type target; values of an assignable type are used directly, numeric values are
+// converted when they fit the target kind and strings are parsed into the target kind.
+func convertValue(target reflect.Type, val any) (reflect.Value, error) {
+	if val == nil || val == PATCH_DELETE {
+		return reflect.Zero(target), nil
+	}
+
+	valOf := reflect.ValueOf(val)
+	if valOf.Type().AssignableTo(target) {
+		ret := reflect.New(target).Elem()
+		ret.Set(valOf)
+		return ret, nil
+	}
+
+	// dereference both sides as required
+	if valOf.Kind() == reflect.Pointer {
+		if valOf.IsNil() {
+			return reflect.Zero(target), nil
+		}
+		return convertValue(target, valOf.Elem().Interface())
+	}
+	if target.Kind() == reflect.Pointer {
+		// the text based formats write nil as an empty value; read it back the same way
+		if str, ok := val.(string); ok && str == "" && target.Elem().Kind() != reflect.String {
+			return reflect.Zero(target), nil
+		}
+
+		elem, err := convertValue(target.Elem(), val)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		ret := reflect.New(target.Elem())
+		ret.Elem().Set(elem)
+		return ret, nil
+	}
+
+	if str, ok := val.(string); ok {
+		return parseString(target, str)
+	}
+
+	switch target.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return convertNumber(target, valOf)
+	case reflect.Slice:
+		if valOf.Kind() != reflect.Slice && valOf.Kind() != reflect.Array {
+			break
+		}
+
+		ret := reflect.MakeSlice(target, valOf.Len(), valOf.Len())
+		for idx := 0; idx < valOf.Len(); idx++ {
+			elem, err := convertValue(target.Elem(), valOf.Index(idx).Interface())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("index %d: %w", idx, err)
+			}
+			ret.Index(idx).Set(elem)
+		}
+		return ret, nil
+	case reflect.Array:
+		if valOf.Kind() != reflect.Slice && valOf.Kind() != reflect.Array {
+			break
+		}
+		if valOf.Len() != target.Len() {
+			return reflect.Value{}, fmt.Errorf("%w: %d values do not fit in %s", ErrOutOfRange, valOf.Len(), target)
+		}
+
+		ret := reflect.New(target).Elem()
+		for idx := 0; idx < valOf.Len(); idx++ {
+			elem, err := convertValue(target.Elem(), valOf.Index(idx).Interface())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("index %d: %w", idx, err)
+			}
+			ret.Index(idx).Set(elem)
+		}
+		return ret, nil
+	case reflect.Map:
+		if valOf.Kind() != reflect.Map {
+			break
+		}
+
+		ret := reflect.MakeMapWithSize(target, valOf.Len())
+		mapItr := valOf.MapRange()
+		for mapItr.Next() {
+			mapKey, err := convertValue(target.Key(), mapItr.Key().Interface())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("map key %v: %w", mapItr.Key(), err)
+			}
+			mapVal, err := convertValue(target.Elem(), mapItr.Value().Interface())
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("map key %v: %w", mapItr.Key(), err)
+			}
+			ret.SetMapIndex(mapKey, mapVal)
+		}
+		return ret, nil
+	}
+
+	if valOf.Kind() == target.Kind() && valOf.Type().ConvertibleTo(target) {
+		return valOf.Convert(target), nil
+	}
+
+	return reflect.Value{}, fmt.Errorf("%w: cannot use %s as %s", ErrTypeMismatch, valOf.Type(), target)
+}
+
+// converts between the numeric kinds, refusing anything that would overflow or lose precision
+func convertNumber(target reflect.Type, valOf reflect.Value) (reflect.Value, error) {
+	ret := reflect.New(target).Elem()
+
+	switch {
+	case valOf.CanInt():
+		num := valOf.Int()
+		switch {
+		case ret.CanInt():
+			if ret.OverflowInt(num) {
+				return reflect.Value{}, fmt.Errorf("%w: %d does not fit in %s", ErrOutOfRange, num, target)
+			}
+			ret.SetInt(num)
+		case ret.CanUint():
+			if num < 0 || ret.OverflowUint(uint64(num)) {
+				return reflect.Value{}, fmt.Errorf("%w: %d does not fit in %s", ErrOutOfRange, num, target)
+			}
+			ret.SetUint(uint64(num))
+		default:
+			ret.SetFloat(float64(num))
+		}
+	case valOf.CanUint():
+		num := valOf.Uint()
+		switch {
+		case ret.CanInt():
+			if num > math.MaxInt64 || ret.OverflowInt(int64(num)) {
+				return reflect.Value{}, fmt.Errorf("%w: %d does not fit in %s", ErrOutOfRange, num, target)
+			}
+			ret.SetInt(int64(num))
+		case ret.CanUint():
+			if ret.OverflowUint(num) {
+				return reflect.Value{}, fmt.Errorf("%w: %d does not fit in %s", ErrOutOfRange, num, target)
+			}
+			ret.SetUint(num)
+		default:
+			ret.SetFloat(float64(num))
+		}
+	case valOf.CanFloat():
+		num := valOf.Float()
+		switch {
+		case ret.CanFloat():
+			if ret.OverflowFloat(num) {
+				return reflect.Value{}, fmt.Errorf("%w: %g does not fit in %s", ErrOutOfRange, num, target)
+			}
+			ret.SetFloat(num)
+		case num != math.Trunc(num) || math.IsInf(num, 0) || math.IsNaN(num):
+			return reflect.Value{}, fmt.Errorf("%w: %g is not a whole number", ErrTypeMismatch, num)
+		case ret.CanInt():
+			if num < math.MinInt64 || num >= math.MaxInt64 || ret.OverflowInt(int64(num)) {
+				return reflect.Value{}, fmt.Errorf("%w: %g does not fit in %s", ErrOutOfRange, num, target)
+			}
+			ret.SetInt(int64(num))
+		default:
+			if num < 0 || num >= math.MaxUint64 || ret.OverflowUint(uint64(num)) {
+				return reflect.Value{}, fmt.Errorf("%w: %g does not fit in %s", ErrOutOfRange, num, target)
+			}
+			ret.SetUint(uint64(num))
+		}
+	default:
+		return reflect.Value{}, fmt.Errorf("%w: cannot use %s as %s", ErrTypeMismatch, valOf.Type(), target)
+	}
+
+	return ret, nil
+}
+
+// Parses the string representation (as produced by internal.ConvertAnyToString) of a value into the target type;
+// an empty string is the zero value of any type that is not a string
+func parseString(target reflect.Type, str string) (reflect.Value, error) {
+	if str == "" && target.Kind() != reflect.String {
+		return reflect.Zero(target), nil
+	}
+
+	if reflect.PointerTo(target).Implements(textUnmarshalerType) {
+		ret := reflect.New(target)
+		if err := ret.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(str)); err != nil {
+			return reflect.Value{}, fmt.Errorf("%w: %v", ErrTypeMismatch, err)
+		}
+		return ret.Elem(), nil
+	}
+
+	ret := reflect.New(target).Elem()
+	var err error
+
+	switch target.Kind() {
+	case reflect.String:
+		ret.SetString(str)
+	case reflect.Bool:
+		var parsed bool
+		parsed, err = strconv.ParseBool(str)
+		ret.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var parsed int64
+		if target == durationType {
+			var dur time.Duration
+			dur, err = time.ParseDuration(str)
+			parsed = int64(dur)
+		} else {
+			parsed, err = strconv.ParseInt(str, 10, target.Bits())
+		}
+		ret.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var parsed uint64
+		parsed, err = strconv.ParseUint(str, 10, target.Bits())
+		ret.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		var parsed float64
+		parsed, err = strconv.ParseFloat(str, target.Bits())
+		ret.SetFloat(parsed)
+	case reflect.Complex64, reflect.Complex128:
+		var parsed complex128
+		parsed, err = strconv.ParseComplex(str, target.Bits())
+		ret.SetComplex(parsed)
+	case reflect.Slice:
+		// a lone value for a slice becomes a single element slice
+		elem, elemErr := parseString(target.Elem(), str)
+		if elemErr != nil {
+			return reflect.Value{}, elemErr
+		}
+		ret = reflect.Append(reflect.MakeSlice(target, 0, 1), elem)
+	case reflect.Interface:
+		if target.NumMethod() != 0 {
+			return reflect.Value{}, fmt.Errorf("%w: cannot use string as %s", ErrTypeMismatch, target)
+		}
+		ret.Set(reflect.ValueOf(str))
+	default:
+		return reflect.Value{}, fmt.Errorf("%w: cannot parse string as %s", ErrTypeMismatch, target)
+	}
+
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return reflect.Value{}, fmt.Errorf("%w: %q does not fit in %s", ErrOutOfRange, str, target)
+		}
+		return reflect.Value{}, fmt.Errorf("%w: cannot parse %q as %s", ErrTypeMismatch, str, target)
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,135 @@
+package struct2map
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// Receives typed attributes from EncodeAttributes; the methods mirror the value types OpenTelemetry attributes
+// support, so adapting to an OTel SDK only takes a small wrapper (ex: SetInt64 appending attribute.Int64(key, val)
+// to a []attribute.KeyValue)
+type AttributeEncoder interface {
+	SetBool(key string, val bool)
+	SetInt64(key string, val int64)
+	SetFloat64(key string, val float64)
+	SetString(key string, val string)
+	SetBoolSlice(key string, val []bool)
+	SetInt64Slice(key string, val []int64)
+	SetFloat64Slice(key string, val []float64)
+	SetStringSlice(key string, val []string)
+}
+
+// Stores slices of plain values whole at their key rather than one key per index (ex: Tags holding []string{"a", "b"}
+// rather than Tags.0 and Tags.1)
+func KeepSlices() Option {
+	return optionFunc(func(cfg *convertConfig) {
+		cfg.keepSlices = true
+	})
+}
+
+// Takes a structure (or any value Convert accepts) and passes each flattened key to enc as a typed attribute, in key
+// order; allows passing of various options (see StructConvertOpts constants, KeepSlices and the other Option returning
+// functions)
+//
+// Values are typed as follows:
+//   - bools as bools and strings as strings
+//   - signed integers widened to int64
+//   - unsigned integers as int64 when they fit, otherwise as their decimal string
+//   - floats widened to float64
+//   - slices kept whole (see KeepSlices) as the slice of the matching type; a slice of unsigned integers that do not
+//     all fit in an int64, or of anything else, as a slice of strings
+//   - anything else is formatted as a string (as ToEnv formats it)
+//
+// Nil values have no attribute representation and are skipped.
+//
+// Returns: nil on success or an error if obj cannot be converted
+func EncodeAttributes(obj any, enc AttributeEncoder, opts ...Option) error {
+	cfg := newConvertConfig(opts...)
+
+	flat := convertToMap(cfg, obj)
+	if flat == nil {
+		return fmt.Errorf("struct2map: cannot convert %T", obj)
+	}
+
+	keys := make([]string, 0, len(flat))
+	for k := range flat {
+		keys = append(keys, k)
+	}
+	sortKeys(keys)
+
+	for _, k := range keys {
+		if flat[k] == nil {
+			continue
+		}
+
+		valOf := reflect.ValueOf(flat[k])
+		if valOf.Kind() == reflect.Slice || valOf.Kind() == reflect.Array {
+			encodeSliceAttribute(cfg, enc, k, valOf)
+			continue
+		}
+
+		switch valOf.Kind() {
+		case reflect.Bool:
+			enc.SetBool(k, valOf.Bool())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			enc.SetInt64(k, valOf.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if valOf.Uint() > math.MaxInt64 {
+				enc.SetString(k, formatValue(cfg, flat[k]))
+			} else {
+				enc.SetInt64(k, int64(valOf.Uint()))
+			}
+		case reflect.Float32, reflect.Float64:
+			enc.SetFloat64(k, valOf.Float())
+		default:
+			enc.SetString(k, formatValue(cfg, flat[k]))
+		}
+	}
+
+	return nil
+}
+
+// passes a slice to enc as the homogeneous slice matching its element type
+func encodeSliceAttribute(cfg *convertConfig, enc AttributeEncoder, key string, valOf reflect.Value) {
+	switch valOf.Type().Elem().Kind() {
+	case reflect.Bool:
+		ret := make([]bool, valOf.Len())
+		for idx := range ret {
+			ret[idx] = valOf.Index(idx).Bool()
+		}
+		enc.SetBoolSlice(key, ret)
+		return
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		ret := make([]int64, valOf.Len())
+		for idx := range ret {
+			ret[idx] = valOf.Index(idx).Int()
+		}
+		enc.SetInt64Slice(key, ret)
+		return
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		ret := make([]int64, valOf.Len())
+		fits := true
+		for idx := range ret {
+			if valOf.Index(idx).Uint() > math.MaxInt64 {
+				fits = false
+				break
+			}
+			ret[idx] = int64(valOf.Index(idx).Uint())
+		}
+		if fits {
+			enc.SetInt64Slice(key, ret)
+			return
+		}
+	case reflect.Float32, reflect.Float64:
+		ret := make([]float64, valOf.Len())
+		for idx := range ret {
+			ret[idx] = valOf.Index(idx).Float()
+		}
+		enc.SetFloat64Slice(key, ret)
+		return
+	}
+
+	items, _ := formatSliceValues(cfg, valOf.Interface())
+	enc.SetStringSlice(key, items)
+}
@@ -0,0 +1,115 @@
+package struct2map
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// records every attribute as "key=type:value"
+type attrTestRecorder []string
+
+func (r *attrTestRecorder) add(key, typ string, val any) {
+	*r = append(*r, fmt.Sprintf("%s=%s:%v", key, typ, val))
+}
+
+func (r *attrTestRecorder) SetBool(key string, val bool)       { r.add(key, "bool", val) }
+func (r *attrTestRecorder) SetInt64(key string, val int64)     { r.add(key, "int64", val) }
+func (r *attrTestRecorder) SetFloat64(key string, val float64) { r.add(key, "float64", val) }
+func (r *attrTestRecorder) SetString(key string, val string)   { r.add(key, "string", val) }
+func (r *attrTestRecorder) SetBoolSlice(key string, val []bool) {
+	r.add(key, "[]bool", val)
+}
+func (r *attrTestRecorder) SetInt64Slice(key string, val []int64) {
+	r.add(key, "[]int64", val)
+}
+func (r *attrTestRecorder) SetFloat64Slice(key string, val []float64) {
+	r.add(key, "[]float64", val)
+}
+func (r *attrTestRecorder) SetStringSlice(key string, val []string) {
+	r.add(key, "[]string", val)
+}
+
+type attrTestSpan struct {
+	Name     string         `struct2map:"name"`
+	Sampled  bool           `struct2map:"sampled"`
+	Retries  int8           `struct2map:"retries"`
+	Bytes    uint64         `struct2map:"bytes"`
+	Huge     uint64         `struct2map:"huge"`
+	Ratio    float32        `struct2map:"ratio"`
+	Timeout  time.Duration  `struct2map:"timeout"`
+	Complex  complex64      `struct2map:"complex"`
+	Parent   *envTestServer `struct2map:"parent"`
+	Tags     []string       `struct2map:"tags"`
+	Flags    []bool         `struct2map:"flags"`
+	Codes    []uint16       `struct2map:"codes"`
+	BigCodes []uint         `struct2map:"bigCodes"`
+	Weights  [2]float64     `struct2map:"weights"`
+}
+
+func Test_EncodeAttributes(t *testing.T) {
+	testSpan := attrTestSpan{
+		Name:     "op",
+		Sampled:  true,
+		Retries:  -2,
+		Bytes:    1024,
+		Huge:     math.MaxUint64,
+		Ratio:    0.5,
+		Timeout:  time.Second,
+		Complex:  1 + 2i,
+		Tags:     []string{"a", "b"},
+		Flags:    []bool{true},
+		Codes:    []uint16{200, 404},
+		BigCodes: []uint{1, math.MaxUint},
+		Weights:  [2]float64{0.25, 1},
+	}
+
+	testSet := []struct {
+		Name          string
+		AttrOpts      []Option
+		ExpectedAttrs attrTestRecorder
+		SkipTest      bool
+	}{
+		{
+			Name: "indexed slices",
+			ExpectedAttrs: attrTestRecorder{
+				"bigCodes.0=int64:1", "bigCodes.1=string:18446744073709551615", "bytes=int64:1024", "codes.0=int64:200",
+				"codes.1=int64:404", "complex=string:(1+2i)", "flags.0=bool:true", "huge=string:18446744073709551615",
+				"name=string:op", "ratio=float64:0.5", "retries=int64:-2", "sampled=bool:true", "tags.0=string:a",
+				"tags.1=string:b", "timeout=int64:1000000000", "weights=[]float64:[0.25 1]",
+			},
+		},
+		{
+			Name:     "slices kept whole",
+			AttrOpts: []Option{KeepSlices()},
+			ExpectedAttrs: attrTestRecorder{
+				"bigCodes=[]string:[1 18446744073709551615]", "bytes=int64:1024", "codes=[]int64:[200 404]",
+				"complex=string:(1+2i)", "flags=[]bool:[true]", "huge=string:18446744073709551615", "name=string:op",
+				"ratio=float64:0.5", "retries=int64:-2", "sampled=bool:true", "tags=[]string:[a b]",
+				"timeout=int64:1000000000", "weights=[]float64:[0.25 1]",
+			},
+		},
+	}
+
+	for _, curTest := range testSet {
+		t.Run(curTest.Name, func(t *testing.T) {
+			if curTest.SkipTest {
+				t.Skipf("skipped '%s' due to SkipTest being set", curTest.Name)
+			}
+
+			var genAttrs attrTestRecorder
+			if err := EncodeAttributes(testSpan, &genAttrs, curTest.AttrOpts...); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(genAttrs, curTest.ExpectedAttrs) {
+				t.Errorf("generated attributes not the same as the expected attributes\nHave: %q\nWant: %q", genAttrs, curTest.ExpectedAttrs)
+			}
+		})
+	}
+
+	if err := EncodeAttributes(1, &attrTestRecorder{}); err == nil {
+		t.Errorf("expected an error encoding a non-structure")
+	}
+}
@@ -0,0 +1,138 @@
+package struct2map
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// Sets the leading columns (by flattened key) WriteCSV writes, in the order given; every other column follows them
+// sorted by key. Columns listed here are written even if no row has a value for them.
+func CSVColumns(columns ...string) Option {
+	return optionFunc(func(cfg *convertConfig) {
+		cfg.csvColumns = columns
+	})
+}
+
+// Takes a slice of structures and writes it to w as CSV with one column per flattened key and one record per row;
+// allows passing of various options (see StructConvertOpts constants and CSVColumns)
+//
+// The columns are the union of the flattened keys of every row, so nested fields, map entries and every index of
+// variable length slices each get a column; rows without a value for a column get an empty cell. The header row
+// lists the keys sorted (unless CSVColumns is passed) so the output is stable between runs.
+//
+// The rows are walked twice, once to collect the columns and once to write them, and only a single flattened row is
+// held in memory at a time. Values are formatted as ToEnv formats them.
+//
+// Returns: nil on success or an error if a row cannot be converted or writing to w fails
+func WriteCSV[T any](w io.Writer, rows []T, opts ...Option) error {
+	cfg := newConvertConfig(opts...)
+
+	seen := make(map[string]bool)
+	for idx := range rows {
+		flat := convertToMap(cfg, rows[idx])
+		if flat == nil {
+			return fmt.Errorf("struct2map: row %d: cannot convert %T", idx+1, rows[idx])
+		}
+		for k := range flat {
+			seen[k] = true
+		}
+	}
+
+	header := csvHeader(cfg, seen)
+	if len(header) == 0 {
+		return nil
+	}
+
+	csvW := csv.NewWriter(w)
+	if err := csvW.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(header))
+	for idx := range rows {
+		flat := convertToMap(cfg, rows[idx])
+		for col, k := range header {
+			record[col] = ""
+			if val, ok := flat[k]; ok {
+				record[col] = formatValue(cfg, val)
+			}
+		}
+
+		if err := csvW.Write(record); err != nil {
+			return err
+		}
+	}
+
+	csvW.Flush()
+	return csvW.Error()
+}
+
+// the CSVColumns columns followed by every other seen key, sorted
+func csvHeader(cfg *convertConfig, seen map[string]bool) []string {
+	header := make([]string, 0, len(cfg.csvColumns)+len(seen))
+	listed := make(map[string]bool, len(cfg.csvColumns))
+	for _, col := range cfg.csvColumns {
+		if !listed[col] {
+			header = append(header, col)
+			listed[col] = true
+		}
+	}
+
+	rest := make([]string, 0, len(seen))
+	for k := range seen {
+		if !listed[k] {
+			rest = append(rest, k)
+		}
+	}
+	sortKeys(rest)
+
+	return append(header, rest...)
+}
+
+// Reads CSV data with a header row of flattened keys (as written by WriteCSV) from r and loads every record into a
+// new T; allows passing of various options (see StructConvertOpts constants), which must match the options used to
+// write it
+//
+// Records are read one at a time and applied as Patch applies them, so columns may appear in any order and unknown
+// columns are reported as errors. Empty cells are skipped, leaving the field at its zero value; this keeps the empty
+// cells WriteCSV writes for short slices and absent map entries from growing them.
+//
+// Returns: the loaded rows or an error for malformed CSV or naming the row of the first record that could not be
+// loaded, wrapping the *KeyError for every key that failed
+func ReadCSV[T any](r io.Reader, opts ...Option) ([]T, error) {
+	csvR := csv.NewReader(r)
+	header, err := csvR.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []T
+	for rowNum := 1; ; rowNum++ {
+		record, err := csvR.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		changes := make(map[string]any, len(record))
+		for col, val := range record {
+			if val != "" {
+				changes[header[col]] = val
+			}
+		}
+
+		var row T
+		if err := patchWithDefaults("ReadCSV", &row, changes, opts...); err != nil {
+			return nil, fmt.Errorf("struct2map: row %d: %w", rowNum, err)
+		}
+		ret = append(ret, row)
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,123 @@
+package struct2map
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type csvTestRecord struct {
+	ID     int               `struct2map:"id"`
+	Name   string            `struct2map:"name"`
+	Server envTestServer     `struct2map:"server"`
+	Tags   []string          `struct2map:"tags"`
+	Labels map[string]string `struct2map:"labels"`
+}
+
+func Test_WriteCSV(t *testing.T) {
+	testRows := []csvTestRecord{
+		{ID: 1, Name: "first", Server: envTestServer{Host: "a", Port: 1}, Tags: []string{"x"}},
+		{ID: 2, Name: "second, with comma", Tags: []string{"y", "z"}, Labels: map[string]string{"team": "core"}},
+	}
+
+	testSet := []struct {
+		Name        string
+		CSVOpts     []Option
+		ExpectedCSV string
+		SkipTest    bool
+	}{
+		{
+			Name: "union of keys sorted",
+			ExpectedCSV: "id,labels.team,name,server.host,server.port,tags.0,tags.1\n" +
+				"1,,first,a,1,x,\n" +
+				"2,core,\"second, with comma\",,0,y,z\n",
+		},
+		{
+			Name:    "configured leading columns",
+			CSVOpts: []Option{CSVColumns("name", "id", "missing")},
+			ExpectedCSV: "name,id,missing,labels.team,server.host,server.port,tags.0,tags.1\n" +
+				"first,1,,,a,1,x,\n" +
+				"\"second, with comma\",2,,core,,0,y,z\n",
+		},
+	}
+
+	for _, curTest := range testSet {
+		t.Run(curTest.Name, func(t *testing.T) {
+			if curTest.SkipTest {
+				t.Skipf("skipped '%s' due to SkipTest being set", curTest.Name)
+			}
+
+			var sb strings.Builder
+			if err := WriteCSV(&sb, testRows, curTest.CSVOpts...); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sb.String() != curTest.ExpectedCSV {
+				t.Errorf("generated CSV not the same as the expected CSV\nHave:\n%s\nWant:\n%s", sb.String(), curTest.ExpectedCSV)
+			}
+
+			// reading back drops nothing but the empty cells
+			readRows, err := ReadCSV[csvTestRecord](strings.NewReader(sb.String()), curTest.CSVOpts...)
+			if err != nil {
+				t.Fatalf("unexpected error reading: %v", err)
+			}
+			if !reflect.DeepEqual(readRows, testRows) {
+				t.Errorf("read rows not the same as the written rows\nHave: %+v\nWant: %+v", readRows, testRows)
+			}
+		})
+	}
+}
+
+func Test_ReadCSV(t *testing.T) {
+	testSet := []struct {
+		Name         string
+		CSV          string
+		ExpectedRows []*csvTestRecord
+		ExpErrKeys   []string
+		SkipTest     bool
+	}{
+		{
+			Name: "columns in any order into pointer rows",
+			CSV:  "tags.1,id,server.port\nb,7,80\n,8,\n",
+			ExpectedRows: []*csvTestRecord{
+				{ID: 7, Server: envTestServer{Port: 80}, Tags: []string{"", "b"}},
+				{ID: 8},
+			},
+		},
+		{
+			Name:       "bad values name the row and keys",
+			CSV:        "id,server.port,nope\n1,2,\nx,y,z\n",
+			ExpErrKeys: []string{"id", "nope", "server.port"},
+		},
+		{
+			Name: "empty input",
+			CSV:  "",
+		},
+	}
+
+	for _, curTest := range testSet {
+		t.Run(curTest.Name, func(t *testing.T) {
+			if curTest.SkipTest {
+				t.Skipf("skipped '%s' due to SkipTest being set", curTest.Name)
+			}
+
+			readRows, err := ReadCSV[*csvTestRecord](strings.NewReader(curTest.CSV))
+			if curTest.ExpErrKeys != nil {
+				if err == nil || !strings.HasPrefix(err.Error(), "struct2map: row 2: ") {
+					t.Fatalf("expected an error naming row 2, got: %v", err)
+				}
+				if errKeys := keyErrorKeys(t, errors.Unwrap(err)); !reflect.DeepEqual(errKeys, curTest.ExpErrKeys) {
+					t.Errorf("failed keys not as expected\nHave: %v\nWant: %v", errKeys, curTest.ExpErrKeys)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(readRows, curTest.ExpectedRows) {
+				t.Errorf("read rows not the same as the expected rows\nHave: %+v\nWant: %+v", readRows, curTest.ExpectedRows)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package struct2map
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var ErrInvalidDefault = errors.New("invalid default value")
+
+// parses the literal of a default tag option into a value of the field type; pointers are allocated
+func parseDefault(t reflect.Type, literal string) (reflect.Value, error) {
+	if t.Kind() != reflect.Pointer {
+		return parseString(t, literal)
+	}
+
+	elem, err := parseDefault(t.Elem(), literal)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	ret := reflect.New(t.Elem())
+	ret.Elem().Set(elem)
+	return ret, nil
+}
+
+// a copy of a parsed default sharing nothing with it, so the cached default can never be modified through a value it
+// was applied to
+func copyDefault(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		ret := reflect.New(v.Type().Elem())
+		ret.Elem().Set(copyDefault(v.Elem()))
+		return ret
+	case reflect.Slice:
+		ret := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for idx := 0; idx < v.Len(); idx++ {
+			ret.Index(idx).Set(copyDefault(v.Index(idx)))
+		}
+		return ret
+	}
+
+	return v
+}
+
+var defaultsErrCache sync.Map // reflect.Type => error
+
+// checks that every default of t, and of the types nested within it, parsed; the result is cached per type
+//
+// Returns: nil or an error joining one naming the field for every default that did not parse
+func defaultsErr(t reflect.Type) error {
+	if cached, ok := defaultsErrCache.Load(t); ok {
+		err, _ := cached.(error)
+		return err
+	}
+
+	var errs []error
+	collectDefaultsErrs(t, map[reflect.Type]bool{}, &errs)
+	err := errors.Join(errs...)
+
+	defaultsErrCache.Store(t, err)
+	return err
+}
+
+func collectDefaultsErrs(t reflect.Type, seen map[reflect.Type]bool, errs *[]error) {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return
+	}
+	seen[t] = true
+
+	for pos, tag := range cachedFieldTags(t) {
+		if tag.skip || tag.unexported {
+			continue
+		}
+
+		if tag.defaultErr != nil {
+			*errs = append(*errs, fmt.Errorf("struct2map: %s.%s: %w", t.Name(), tag.goName, tag.defaultErr))
+			continue
+		}
+		collectDefaultsErrs(t.Field(pos).Type, seen, errs)
+	}
+}
+
+// Returns a copy of cur (found at segs) with every zero (or nil) field that has a default set to it, following nested
+// structures (directly or through non-nil pointers); cur itself is never modified. Fields whose key is in the input
+// (ex: a key found at or below it, see inputKeys) are left alone, as the input is applied over them. changed is false
+// (and cur returned as is) if no default applied.
+func applyDefaults(cfg *convertConfig, cur reflect.Value, segs []string, input map[string]bool) (ret reflect.Value, changed bool) {
+	switch cur.Kind() {
+	case reflect.Pointer:
+		if cur.IsNil() {
+			return cur, false
+		}
+
+		newElem, changed := applyDefaults(cfg, cur.Elem(), segs, input)
+		if !changed {
+			return cur, false
+		}
+
+		ret := reflect.New(cur.Type().Elem())
+		ret.Elem().Set(newElem)
+		return ret, true
+	case reflect.Struct:
+		if cur.Type() == timeType {
+			return cur, false
+		}
+	default:
+		return cur, false
+	}
+
+	for pos, tag := range cachedFieldTags(cur.Type()) {
+		tag = configureFieldTag(cfg, tag)
+		if tag.skip || tag.unexported {
+			continue
+		}
+
+		// as in structToMap, ignoring parents drops the prefix for this field and every field after it
+		if tag.ignoreParents {
+			segs = nil
+		}
+		fieldSegs := appendSeg(segs, fieldKeyName(cfg, tag))
+
+		field := cur.Field(pos)
+		newVal := reflect.Value{}
+		if tag.defaultValue.IsValid() && field.IsZero() {
+			if input[joinKey(fieldSegs)] {
+				continue
+			}
+			newVal = copyDefault(tag.defaultValue)
+		} else if nested, nestedChanged := applyDefaults(cfg, field, fieldSegs, input); nestedChanged {
+			newVal = nested
+		} else {
+			continue
+		}
+
+		if !ret.IsValid() {
+			ret = reflect.New(cur.Type()).Elem()
+			ret.Set(cur)
+		}
+		ret.Field(pos).Set(newVal)
+	}
+
+	if !ret.IsValid() {
+		return cur, false
+	}
+
+	return ret, true
+}
+
+// applies the defaults to the zero fields of the value dest points to that changes has no key for, and then changes as
+// Patch applies them, skipping redacted keys; this is how the reverse conversions that build a value (ex:
+// FromURLValues, Into) treat missing keys
+func patchWithDefaults(funcName string, dest any, changes map[string]any, opts ...Option) error {
+	cfg := newConvertConfig(opts...)
+
+	target, err := targetValue(funcName, dest)
+	if err != nil {
+		return err
+	}
+	if err := defaultsErr(target.Type()); err != nil {
+		return err
+	}
+
+	// redacted keys hold the replacement ConvertStruct stored, never the value
+	changes = withoutRedactedKeys(cfg, target.Type(), changes)
+	keys := make([]string, 0, len(changes))
+	for k := range changes {
+		if changes[k] != PATCH_NOOP {
+			keys = append(keys, k)
+		}
+	}
+
+	working, _ := applyDefaults(cfg, target, nil, inputKeys(keys))
+	return patchValue(cfg, target, working, changes)
+}
+
+// the keys given and every key above them (ex: a, a.b and a.b.c for a.b.c), so a field is in the input if a key was
+// given at or below it
+func inputKeys(keys []string) map[string]bool {
+	ret := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		segs := splitKey(k)
+		for idx := range segs {
+			ret[joinKey(segs[:idx+1])] = true
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,239 @@
+package struct2map
+
+import (
+	"errors"
+	"flag"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type defaultsTestServer struct {
+	Host string `struct2map:"host,default=localhost"`
+	Port int    `struct2map:"port,required,default=8080"`
+}
+
+type defaultsTestConfig struct {
+	Name    string              `struct2map:"name,required"`
+	Level   string              `struct2map:"level,default=info"`
+	Retries *int                `struct2map:"retries,default=3"`
+	Timeout time.Duration       `struct2map:"timeout,default=1m30s"`
+	Ratio   float64             `struct2map:"ratio,default=0.5"`
+	Tags    []string            `struct2map:"tags,default=base"`
+	Started time.Time           `struct2map:"started,default=2024-01-02T03:04:05Z"`
+	Server  defaultsTestServer  `struct2map:"server"`
+	Backup  *defaultsTestServer `struct2map:"backup,omitempty"`
+}
+
+type defaultsTestBad struct {
+	Name  string `struct2map:"name"`
+	Port  int    `struct2map:"port,default=eighty"`
+	Inner struct {
+		On bool `struct2map:"on,default=maybe"`
+	} `struct2map:"inner"`
+}
+
+func newDefaultsTestConfig() defaultsTestConfig {
+	retries := 3
+	return defaultsTestConfig{
+		Level:   "info",
+		Retries: &retries,
+		Timeout: 90 * time.Second,
+		Ratio:   0.5,
+		Tags:    []string{"base"},
+		Started: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Server:  defaultsTestServer{Host: "localhost", Port: 8080},
+	}
+}
+
+func Test_DefaultsConvert(t *testing.T) {
+	retries := 0
+	testSet := []struct {
+		Name     string
+		Input    any
+		Opts     []Option
+		Expected map[string]any
+		SkipTest bool
+	}{
+		{
+			Name:  "zero values take their defaults",
+			Input: defaultsTestConfig{},
+			Expected: map[string]any{
+				"name": "", "level": "info", "retries": 3, "timeout": 90 * time.Second, "ratio": 0.5, "tags.0": "base",
+				"started": time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), "server.host": "localhost", "server.port": 8080,
+			},
+		},
+		{
+			Name:  "set values are kept",
+			Input: defaultsTestConfig{Name: "svc", Level: "debug", Retries: &retries, Tags: []string{}, Server: defaultsTestServer{Port: 1}, Backup: &defaultsTestServer{Host: "b"}},
+			Expected: map[string]any{
+				"name": "svc", "level": "debug", "retries": 0, "timeout": 90 * time.Second, "ratio": 0.5,
+				"started": time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), "server.host": "localhost", "server.port": 1,
+				"backup.host": "b", "backup.port": 8080,
+			},
+		},
+		{
+			Name:  "defaults that do not parse are ignored",
+			Input: defaultsTestBad{},
+			Expected: map[string]any{
+				"name": "", "port": 0, "inner.on": false,
+			},
+		},
+	}
+
+	for _, test := range testSet {
+		t.Run(test.Name, func(t *testing.T) {
+			if test.SkipTest {
+				t.Skipf("skipping test")
+			}
+
+			res := Convert(test.Input, test.Opts...)
+			if !reflect.DeepEqual(res, test.Expected) {
+				t.Fatalf("result mismatch\nexpected: %v\ngot:      %v", test.Expected, res)
+			}
+		})
+	}
+
+	// the cached defaults must never be modified through a converted value
+	kept := Convert(defaultsTestConfig{}, KeepSlices())
+	kept["tags"].([]string)[0] = "changed"
+	if res := ConvertStruct(defaultsTestConfig{}); res["tags.0"] != "base" {
+		t.Fatalf("cached default was modified: %v", res["tags.0"])
+	}
+}
+
+func Test_DefaultsPlanErrors(t *testing.T) {
+	_, err := ConvertOf(defaultsTestBad{})
+	if !errors.Is(err, ErrInvalidDefault) || !strings.Contains(err.Error(), "defaultsTestBad.Port") {
+		t.Fatalf("expected ErrInvalidDefault naming the field, got %v", err)
+	}
+	if !strings.Contains(err.Error(), ".On") {
+		t.Fatalf("expected the nested field to be reported as well, got %v", err)
+	}
+
+	if _, err := Into[*defaultsTestBad](map[string]any{"name": "a"}); !errors.Is(err, ErrInvalidDefault) {
+		t.Fatalf("expected ErrInvalidDefault from Into, got %v", err)
+	}
+	if res := Validate[defaultsTestBad](map[string]any{}); len(res) != 1 || !errors.Is(res[0], ErrInvalidDefault) {
+		t.Fatalf("expected ErrInvalidDefault from Validate, got %v", res)
+	}
+
+	var dest defaultsTestBad
+	if err := FromURLValues(url.Values{"name": {"a"}}, &dest); !errors.Is(err, ErrInvalidDefault) || dest.Name != "" {
+		t.Fatalf("expected ErrInvalidDefault from FromURLValues with dest untouched, got %v (%+v)", err, dest)
+	}
+	if err := FromEnv(&dest, EnvSource([]string{"NAME=a"})); !errors.Is(err, ErrInvalidDefault) {
+		t.Fatalf("expected ErrInvalidDefault from FromEnv, got %v", err)
+	}
+}
+
+func Test_DefaultsLoad(t *testing.T) {
+	t.Run("Into", func(t *testing.T) {
+		res, err := Into[defaultsTestConfig](map[string]any{"name": "svc", "ratio": 0, "server.host": "h"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// keys that are present win, even when they hold the zero value
+		expected := newDefaultsTestConfig()
+		expected.Name, expected.Ratio, expected.Server.Host = "svc", 0, "h"
+		if !reflect.DeepEqual(res, expected) {
+			t.Fatalf("result mismatch\nexpected: %+v\ngot:      %+v", expected, res)
+		}
+	})
+
+	t.Run("keys below a default replace it", func(t *testing.T) {
+		res, err := Into[defaultsTestConfig](map[string]any{"tags.1": "x", "level": PATCH_NOOP})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(res.Tags, []string{"", "x"}) || res.Level != "info" {
+			t.Fatalf("unexpected result: %+v", res)
+		}
+
+		var dest defaultsTestConfig
+		if err := FromURLValues(url.Values{"name": {"svc"}, "tags.1": {"y"}}, &dest); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(dest.Tags, []string{"", "y"}) {
+			t.Fatalf("unexpected result: %+v", dest)
+		}
+	})
+
+	t.Run("FromEnv", func(t *testing.T) {
+		// a required field with a default is never missing
+		dest := defaultsTestConfig{Level: "warn"}
+		if err := FromEnv(&dest, EnvSource([]string{"NAME=svc", "SERVER_HOST=h"})); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := newDefaultsTestConfig()
+		expected.Name, expected.Level, expected.Server.Host = "svc", "warn", "h"
+		if !reflect.DeepEqual(dest, expected) {
+			t.Fatalf("result mismatch\nexpected: %+v\ngot:      %+v", expected, dest)
+		}
+	})
+
+	t.Run("FromURLValues", func(t *testing.T) {
+		var dest defaultsTestConfig
+		if err := FromURLValues(url.Values{"name": {"svc"}, "tags": {"a", "b"}}, &dest); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := newDefaultsTestConfig()
+		expected.Name, expected.Tags = "svc", []string{"a", "b"}
+		if !reflect.DeepEqual(dest, expected) {
+			t.Fatalf("result mismatch\nexpected: %+v\ngot:      %+v", expected, dest)
+		}
+	})
+
+	t.Run("ReadCSV", func(t *testing.T) {
+		rows, err := ReadCSV[defaultsTestServer](strings.NewReader("host,port\na,\n,1\n"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := []defaultsTestServer{{Host: "a", Port: 8080}, {Host: "localhost", Port: 1}}
+		if !reflect.DeepEqual(rows, expected) {
+			t.Fatalf("result mismatch\nexpected: %+v\ngot:      %+v", expected, rows)
+		}
+	})
+
+	t.Run("BindFlags", func(t *testing.T) {
+		// the defaults are only shown as the flag defaults; nothing is written to dest until a flag is parsed
+		var dest defaultsTestServer
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		if err := BindFlags(fs, &dest); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dest != (defaultsTestServer{}) {
+			t.Fatalf("dest changed by binding: %+v", dest)
+		}
+		if err := fs.Parse([]string{"-host", "h"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if fs.Lookup("port").DefValue != "8080" || fs.Lookup("host").DefValue != "localhost" || dest != (defaultsTestServer{Host: "h"}) {
+			t.Fatalf("unexpected result: %+v (port default %q)", dest, fs.Lookup("port").DefValue)
+		}
+	})
+
+	t.Run("Patch leaves missing keys alone", func(t *testing.T) {
+		var dest defaultsTestServer
+		if err := Patch(&dest, map[string]any{"host": "h"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if dest != (defaultsTestServer{Host: "h"}) {
+			t.Fatalf("defaults applied by Patch: %+v", dest)
+		}
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		res := Validate[defaultsTestConfig](map[string]any{"level": "debug"})
+		if len(res) != 1 || res[0].Key != "name" || !errors.Is(res[0], ErrMissingRequired) {
+			t.Fatalf("expected only name to be missing, got %v", res)
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package struct2map
+
+import (
+	"math"
+	"reflect"
+)
+
+type ChangeType uint
+
+const (
+	DIFF_CHANGE_ADDED    ChangeType = iota // key only exists in the new (b) structure
+	DIFF_CHANGE_REMOVED                    // key only exists in the old (a) structure
+	DIFF_CHANGE_MODIFIED                   // key exists in both structures but the values differ
+)
+
+func (ct ChangeType) String() string {
+	switch ct {
+	case DIFF_CHANGE_ADDED:
+		return "added"
+	case DIFF_CHANGE_REMOVED:
+		return "removed"
+	case DIFF_CHANGE_MODIFIED:
+		return "modified"
+	}
+
+	return "unknown"
+}
+
+// A single difference between two flattened structures; Old is always nil for DIFF_CHANGE_ADDED
+// and New is always nil for DIFF_CHANGE_REMOVED
+type Change struct {
+	Key  string
+	Type ChangeType
+	Old  any
+	New  any
+}
+
+// Ignores the given flattened key paths when diffing; a path also ignores every key nested beneath it
+// (ex: "Server" ignores both "Server" and "Server.Port")
+func DiffIgnorePaths(paths ...string) Option {
+	return optionFunc(func(cfg *convertConfig) {
+		cfg.diffIgnorePaths = append(cfg.diffIgnorePaths, paths...)
+	})
+}
+
+// Treats a missing key, a nil value and an empty slice or map value as equal when diffing
+func DiffNilEqualsEmpty() Option {
+	return optionFunc(func(cfg *convertConfig) {
+		cfg.diffNilEqualsEmpty = true
+	})
+}
+
+// Considers two float values equal when they are within tolerance of each other when diffing
+func DiffFloatTolerance(tolerance float64) Option {
+	return optionFunc(func(cfg *convertConfig) {
+		cfg.diffFloatTolerance = math.Abs(tolerance)
+	})
+}
+
+// Flattens a and b with the same rules as ConvertStruct and reports the keys that were added, removed or modified
+// going from a to b; allows passing of various options (see StructConvertOpts constants and the Diff* options)
+//
+// Returns: the list of changes sorted by key or nil if there are no differences
+func Diff(a, b any, opts ...Option) []Change {
+	cfg := newConvertConfig(opts...)
+
+	oldMap := structToMap(cfg, "", reflect.ValueOf(a))
+	newMap := structToMap(cfg, "", reflect.ValueOf(b))
+
+	keys := make([]string, 0, len(oldMap)+len(newMap))
+	for k := range oldMap {
+		keys = append(keys, k)
+	}
+	for k := range newMap {
+		if _, ok := oldMap[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sortKeys(keys)
+
+	var ret []Change
+DIFF_KEY_PROC:
+	for _, k := range keys {
+		for _, ignore := range cfg.diffIgnorePaths {
+			if keyHasPrefix(k, ignore) {
+				continue DIFF_KEY_PROC
+			}
+		}
+
+		oldVal, inOld := oldMap[k]
+		newVal, inNew := newMap[k]
+
+		if cfg.diffNilEqualsEmpty && (!inOld || isEmptyValue(oldVal)) && (!inNew || isEmptyValue(newVal)) {
+			continue
+		}
+
+		switch {
+		case !inOld:
+			ret = append(ret, Change{Key: k, Type: DIFF_CHANGE_ADDED, New: newVal})
+		case !inNew:
+			ret = append(ret, Change{Key: k, Type: DIFF_CHANGE_REMOVED, Old: oldVal})
+		case !valuesEqual(cfg, oldVal, newVal):
+			ret = append(ret, Change{Key: k, Type: DIFF_CHANGE_MODIFIED, Old: oldVal, New: newVal})
+		}
+	}
+
+	return ret
+}
+
+// nil, or a nil/zero-length slice or map
+func isEmptyValue(val any) bool {
+	if val == nil {
+		return true
+	}
+
+	valOf := reflect.ValueOf(val)
+	switch valOf.Kind() {
+	case reflect.Slice, reflect.Map:
+		return valOf.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return valOf.IsNil()
+	}
+
+	return false
+}
+
+func valuesEqual(cfg *convertConfig, a, b any) bool {
+	if cfg.diffFloatTolerance > 0 {
+		aVal := reflect.ValueOf(a)
+		bVal := reflect.ValueOf(b)
+		if aVal.CanFloat() && bVal.CanFloat() {
+			return math.Abs(aVal.Float()-bVal.Float()) <= cfg.diffFloatTolerance
+		}
+	}
+
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,147 @@
+package struct2map
+
+import (
+	"reflect"
+	"testing"
+)
+
+type diffTestServer struct {
+	Host string
+	Port int
+}
+
+type diffTestConfig struct {
+	Name    string
+	Ratio   float64
+	Server  diffTestServer
+	Tags    []string
+	Labels  map[string]string
+	Backup  *diffTestServer
+	Comment *string `struct2map:"comment"`
+	Extra   map[string]any
+}
+
+func Test_Diff(t *testing.T) {
+	tenth, fifth := 0.1, 0.2
+	base := diffTestConfig{
+		Name:   "svc",
+		Ratio:  0.5,
+		Server: diffTestServer{Host: "localhost", Port: 80},
+		Tags:   []string{"a", "b"},
+		Labels: map[string]string{"team": "core"},
+	}
+
+	testSet := []struct {
+		Name       string
+		Old        any
+		New        any
+		DiffOpts   []Option
+		ExpChanges []Change
+		SkipTest   bool
+	}{
+		{
+			Name: "identical structs have no changes",
+			Old:  base,
+			New:  &base,
+		},
+		{
+			Name: "modified, added and removed keys",
+			Old:  base,
+			New: diffTestConfig{
+				Name:   "svc",
+				Ratio:  0.5,
+				Server: diffTestServer{Host: "localhost", Port: 8080},
+				Tags:   []string{"a"},
+				Labels: map[string]string{"team": "core", "env": "prod"},
+			},
+			ExpChanges: []Change{
+				{Key: "Labels.env", Type: DIFF_CHANGE_ADDED, New: "prod"},
+				{Key: "Server.Port", Type: DIFF_CHANGE_MODIFIED, Old: 80, New: 8080},
+				{Key: "Tags.1", Type: DIFF_CHANGE_REMOVED, Old: "b"},
+			},
+		},
+		{
+			Name:     "ignored paths are not reported",
+			Old:      base,
+			New:      diffTestConfig{Name: "svc2", Ratio: 0.5, Server: diffTestServer{Host: "remote", Port: 81}, Tags: []string{"a", "b"}, Labels: map[string]string{"team": "core"}},
+			DiffOpts: []Option{DiffIgnorePaths("Server")},
+			ExpChanges: []Change{
+				{Key: "Name", Type: DIFF_CHANGE_MODIFIED, Old: "svc", New: "svc2"},
+			},
+		},
+		{
+			Name: "nil pointer struct versus empty struct is reported by default",
+			Old:  diffTestConfig{},
+			New:  diffTestConfig{Backup: &diffTestServer{}},
+			ExpChanges: []Change{
+				{Key: "Backup", Type: DIFF_CHANGE_REMOVED},
+				{Key: "Backup.Host", Type: DIFF_CHANGE_ADDED, New: ""},
+				{Key: "Backup.Port", Type: DIFF_CHANGE_ADDED, New: 0},
+			},
+		},
+		{
+			Name:     "nil equals empty",
+			Old:      diffTestConfig{Extra: map[string]any{"a": nil, "b": []int{}}},
+			New:      diffTestConfig{Extra: map[string]any{"a": map[string]int{}}},
+			DiffOpts: []Option{DiffNilEqualsEmpty()},
+		},
+		{
+			Name: "containers within map entries are compared whole",
+			Old:  diffTestConfig{Extra: map[string]any{"a": []int{1, 2}, "b": map[string]int{"x": 1}}},
+			New:  diffTestConfig{Extra: map[string]any{"a": []int{1, 3}, "b": map[string]int{"x": 1}}},
+			ExpChanges: []Change{
+				{Key: "Extra.a", Type: DIFF_CHANGE_MODIFIED, Old: []int{1, 2}, New: []int{1, 3}},
+			},
+		},
+		{
+			Name:     "float tolerance",
+			Old:      diffTestConfig{Ratio: 0.3},
+			New:      diffTestConfig{Ratio: tenth + fifth},
+			DiffOpts: []Option{DiffFloatTolerance(1e-9)},
+		},
+		{
+			Name: "float without tolerance",
+			Old:  diffTestConfig{Ratio: 0.3},
+			New:  diffTestConfig{Ratio: tenth + fifth},
+			ExpChanges: []Change{
+				{Key: "Ratio", Type: DIFF_CHANGE_MODIFIED, Old: 0.3, New: tenth + fifth},
+			},
+		},
+		{
+			Name:     "convert options apply to the reported keys",
+			Old:      diffTestConfig{Server: diffTestServer{Port: 1}},
+			New:      diffTestConfig{Server: diffTestServer{Port: 2}},
+			DiffOpts: []Option{STRUCT_CONVERT_MAPKEY_SNAKE},
+			ExpChanges: []Change{
+				{Key: "server.port", Type: DIFF_CHANGE_MODIFIED, Old: 1, New: 2},
+			},
+		},
+	}
+
+	for _, curTest := range testSet {
+		t.Run(curTest.Name, func(t *testing.T) {
+			if curTest.SkipTest {
+				t.Skipf("skipped '%s' due to SkipTest being set", curTest.Name)
+			}
+
+			changes := Diff(curTest.Old, curTest.New, curTest.DiffOpts...)
+			if !reflect.DeepEqual(changes, curTest.ExpChanges) {
+				t.Errorf("generated changes not the same as the expected changes\nHave: %+v\nWant: %+v", changes, curTest.ExpChanges)
+			}
+		})
+	}
+}
+
+func Test_DiffKeyOrdering(t *testing.T) {
+	old := diffTestConfig{}
+	new := diffTestConfig{Tags: make([]string, 11)}
+
+	changes := Diff(old, new)
+	if len(changes) != 11 {
+		t.Fatalf("expected 11 changes, got %d", len(changes))
+	}
+
+	if changes[2].Key != "Tags.2" || changes[10].Key != "Tags.10" {
+		t.Errorf("changes are not sorted by their numeric index: %+v", changes)
+	}
+}
@@ -0,0 +1,179 @@
+package struct2map
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+const DEFAULT_ENV_SEPARATOR = "_"
+
+// Prefixes every environment variable name with prefix (ex: "APP" turns Server.Port into APP_SERVER_PORT)
+func EnvPrefix(prefix string) Option {
+	return optionFunc(func(cfg *convertConfig) {
+		cfg.envPrefix = prefix
+	})
+}
+
+// Sets the separator placed between the segments of environment variable names (default: DEFAULT_ENV_SEPARATOR)
+func EnvSeparator(sep string) Option {
+	return optionFunc(func(cfg *convertConfig) {
+		cfg.envSeparator = sep
+	})
+}
+
+// Stores slices of plain values as a single environment variable with the items joined by sep
+// (ex: APP_HOSTS=a,b) rather than one variable per index (ex: APP_HOSTS_0=a, APP_HOSTS_1=b)
+func EnvSliceSeparator(sep string) Option {
+	return optionFunc(func(cfg *convertConfig) {
+		cfg.envSliceSeparator = sep
+	})
+}
+
+// Sets the KEY=value list (as returned by os.Environ) FromEnv loads from instead of the process environment
+func EnvSource(environ []string) Option {
+	return optionFunc(func(cfg *convertConfig) {
+		cfg.envSource = environ
+	})
+}
+
+// Sets the function FromEnv looks up variables with (ex: os.LookupEnv); as a lookup function cannot list every
+// variable, map entries are only loaded if EnvSource is passed as well
+func EnvLookup(lookup func(name string) (string, bool)) Option {
+	return optionFunc(func(cfg *convertConfig) {
+		cfg.envLookup = lookup
+	})
+}
+
+// Takes a structure (or any value Convert accepts) and encodes it as a list of environment variables in the
+// KEY=value form used by os.Environ and exec.Cmd.Env; allows passing of various options (see the Env* options)
+//
+// Variable names are the flattened keys with each segment converted to SCREAMING_SNAKE case, joined by the
+// separator and prefixed with the prefix if set (ex: Server.Port becomes APP_SERVER_PORT). Values are formatted
+// as follows:
+//   - bools as true or false
+//   - floats in their shortest representation that parses back to the same value (ex: 0.5, 1e+21)
+//   - slices and arrays one variable per index, or joined into one variable when EnvSliceSeparator is passed
+//   - nil values (ex: nil pointers) as an empty value (ex: APP_BACKUP=)
+//
+// Returns: the KEY=value list sorted by key or nil on error (see Convert)
+func ToEnv(obj any, opts ...Option) []string {
+	cfg := newConvertConfig(opts...)
+	cfg.keepSlices = cfg.envSliceSeparator != ""
+
+	flat := convertToMap(cfg, obj)
+	if flat == nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(flat))
+	for k := range flat {
+		keys = append(keys, k)
+	}
+	sortKeys(keys)
+
+	ret := make([]string, 0, len(keys))
+	for _, k := range keys {
+		value := formatValue(cfg, flat[k])
+		if items, ok := formatSliceValues(cfg, flat[k]); ok {
+			// values stored whole (ex: arrays) have nothing to join their items with unless a separator is set
+			if cfg.envSliceSeparator == "" {
+				for idx, item := range items {
+					ret = append(ret, fmt.Sprintf("%s=%s", envName(cfg, append(splitKey(k), strconv.Itoa(idx))), item))
+				}
+				continue
+			}
+			value = strings.Join(items, cfg.envSliceSeparator)
+		}
+
+		ret = append(ret, fmt.Sprintf("%s=%s", envName(cfg, splitKey(k)), value))
+	}
+
+	return ret
+}
+
+// builds the environment variable name for the given key segments
+func envName(cfg *convertConfig, segs []string) string {
+	parts := make([]string, 0, len(segs)+1)
+	if cfg.envPrefix != "" {
+		parts = append(parts, strcase.ToScreamingSnake(cfg.envPrefix))
+	}
+	for _, seg := range segs {
+		parts = append(parts, strcase.ToScreamingSnake(seg))
+	}
+
+	return strings.Join(parts, envSeparator(cfg))
+}
+
+func envSeparator(cfg *convertConfig) string {
+	if cfg.envSeparator == "" {
+		return DEFAULT_ENV_SEPARATOR
+	}
+
+	return cfg.envSeparator
+}
+
+// Populates the structure pointed to by dest from environment variables named the same way ToEnv names them;
+// allows passing of various options (see StructConvertOpts constants and the Env* options)
+//
+// Loads from the process environment unless EnvSource or EnvLookup is passed. Slices are loaded from indexed
+// variables (ex: APP_HOSTS_0, APP_HOSTS_1, ...) or, if EnvSliceSeparator is passed, from a single joined variable
+// (ex: APP_HOSTS=a,b). Map entries holding plain values are loaded from every variable below the map's name, with
+// the rest of the name as the map key (ex: APP_LABELS_TEAM sets the key TEAM), unless the map already holds a key
+// named that way (ex: team), which is reused. Fields tagged with the required option must have at least one variable
+// found for them; within slice items and pointers to structures that is only checked once anything is found for the
+// item or pointer, or the pointer is tagged required itself.
+//
+// Returns: nil on success or an error joining a *KeyError, naming the offending variable, for every value that could
+// not be parsed into its field and every missing required field; on error dest is left untouched
+func FromEnv(dest any, opts ...Option) error {
+	cfg := newConvertConfig(opts...)
+
+	target, err := targetValue("FromEnv", dest)
+	if err != nil {
+		return err
+	}
+
+	environ := cfg.envSource
+	if environ == nil && cfg.envLookup == nil {
+		environ = os.Environ()
+	}
+
+	src := nameSource{lookup: func(name string) ([]string, bool) {
+		val, ok := cfg.envLookup(name)
+		return []string{val}, ok
+	}}
+	if environ != nil {
+		envMap := make(map[string]string, len(environ))
+		for _, kv := range environ {
+			name, val, _ := strings.Cut(kv, "=")
+			envMap[name] = val
+			src.names = append(src.names, name)
+		}
+		sort.Strings(src.names)
+
+		if cfg.envLookup == nil {
+			src.lookup = func(name string) ([]string, bool) {
+				val, ok := envMap[name]
+				return []string{val}, ok
+			}
+		}
+	}
+
+	loader := &nameLoader{
+		cfg:        cfg,
+		src:        src,
+		nameOf:     func(segs []string) string { return envName(cfg, segs) },
+		sep:        envSeparator(cfg),
+		sliceSep:   cfg.envSliceSeparator,
+		inProgress: make(map[reflect.Type]bool),
+	}
+	loader.walk(target.Type(), target, nil)
+
+	return loader.apply(target)
+}
@@ -0,0 +1,296 @@
+package struct2map
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type envTestServer struct {
+	Host string `struct2map:"host"`
+	Port int    `struct2map:"port"`
+}
+
+type envTestConfig struct {
+	Debug     bool              `struct2map:"debug"`
+	Ratio     float64           `struct2map:"ratio"`
+	Server    envTestServer     `struct2map:"server"`
+	Backup    *envTestServer    `struct2map:"backup"`
+	Hosts     []string          `struct2map:"hosts"`
+	Labels    map[string]string `struct2map:"labels"`
+	MaxConns  uint              `struct2map:"maxConns"`
+	Endpoints []envTestServer   `struct2map:"endpoints"`
+	Pair      [2]string         `struct2map:"pair"`
+}
+
+func Test_ToEnv(t *testing.T) {
+	testStruct := envTestConfig{
+		Debug:     true,
+		Ratio:     0.25,
+		Server:    envTestServer{Host: "localhost", Port: 8080},
+		Hosts:     []string{"a", "b"},
+		Labels:    map[string]string{"team": "core"},
+		MaxConns:  10,
+		Endpoints: []envTestServer{{Host: "e0", Port: 1}},
+		Pair:      [2]string{"a", "b"},
+	}
+
+	testSet := []struct {
+		Name        string
+		TestStruct  any
+		EnvOpts     []Option
+		ExpectedEnv []string
+		SkipTest    bool
+	}{
+		{
+			Name:       "indexed slices with prefix",
+			TestStruct: testStruct,
+			EnvOpts:    []Option{EnvPrefix("app")},
+			ExpectedEnv: []string{
+				"APP_BACKUP=",
+				"APP_DEBUG=true",
+				"APP_ENDPOINTS_0_HOST=e0",
+				"APP_ENDPOINTS_0_PORT=1",
+				"APP_HOSTS_0=a",
+				"APP_HOSTS_1=b",
+				"APP_LABELS_TEAM=core",
+				"APP_MAX_CONNS=10",
+				"APP_PAIR_0=a",
+				"APP_PAIR_1=b",
+				"APP_RATIO=0.25",
+				"APP_SERVER_HOST=localhost",
+				"APP_SERVER_PORT=8080",
+			},
+		},
+		{
+			Name:       "joined slices and custom separator",
+			TestStruct: &testStruct,
+			EnvOpts:    []Option{EnvSliceSeparator(","), EnvSeparator("__")},
+			ExpectedEnv: []string{
+				"BACKUP=",
+				"DEBUG=true",
+				"ENDPOINTS__0__HOST=e0",
+				"ENDPOINTS__0__PORT=1",
+				"HOSTS=a,b",
+				"LABELS__TEAM=core",
+				"MAX_CONNS=10",
+				"PAIR=a,b",
+				"RATIO=0.25",
+				"SERVER__HOST=localhost",
+				"SERVER__PORT=8080",
+			},
+		},
+		{
+			Name:        "not a structure",
+			TestStruct:  1,
+			ExpectedEnv: nil,
+		},
+	}
+
+	for _, curTest := range testSet {
+		t.Run(curTest.Name, func(t *testing.T) {
+			if curTest.SkipTest {
+				t.Skipf("skipped '%s' due to SkipTest being set", curTest.Name)
+			}
+
+			genEnv := ToEnv(curTest.TestStruct, curTest.EnvOpts...)
+			if !reflect.DeepEqual(genEnv, curTest.ExpectedEnv) {
+				t.Errorf("generated environment not the same as the expected environment\nHave: %q\nWant: %q", genEnv, curTest.ExpectedEnv)
+			}
+		})
+	}
+}
+
+type envTestRequired struct {
+	Name   string        `struct2map:"name,required"`
+	Server envTestServer `struct2map:"server,required"`
+}
+
+type envTestFlattened struct {
+	Meta struct {
+		ID   string `struct2map:"id,ignoreparents"`
+		Zone string `struct2map:"zone"`
+	} `struct2map:"meta"`
+	Name string `struct2map:"name"`
+}
+
+type envTestOptional struct {
+	Hosts    []envTestRequired `struct2map:"hosts"`
+	Opt      *envTestRequired  `struct2map:"opt"`
+	Must     *envTestRequired  `struct2map:"must,required"`
+	Optional int               `struct2map:"optional"`
+}
+
+func Test_FromEnv(t *testing.T) {
+	testSet := []struct {
+		Name        string
+		Environ     []string
+		EnvOpts     []Option
+		Dest        any
+		ExpectedVal any
+		ExpErrKeys  []string
+		SkipTest    bool
+	}{
+		{
+			Name: "indexed slices, maps and nested structs",
+			Environ: []string{
+				"APP_DEBUG=true", "APP_RATIO=0.5", "APP_SERVER_HOST=localhost", "APP_SERVER_PORT=8080",
+				"APP_BACKUP_PORT=9090", "APP_HOSTS_0=a", "APP_HOSTS_1=b", "APP_HOSTS_3=skipped",
+				"APP_LABELS_TEAM=core", "APP_LABELS_ENV=prod", "APP_MAX_CONNS=10",
+				"APP_ENDPOINTS_0_HOST=e0", "APP_ENDPOINTS_1_PORT=2", "OTHER_DEBUG=false",
+			},
+			EnvOpts: []Option{EnvPrefix("APP")},
+			Dest:    &envTestConfig{},
+			ExpectedVal: &envTestConfig{
+				Debug:     true,
+				Ratio:     0.5,
+				Server:    envTestServer{Host: "localhost", Port: 8080},
+				Backup:    &envTestServer{Port: 9090},
+				Hosts:     []string{"a", "b"},
+				Labels:    map[string]string{"TEAM": "core", "ENV": "prod"},
+				MaxConns:  10,
+				Endpoints: []envTestServer{{Host: "e0"}, {Port: 2}},
+			},
+		},
+		{
+			Name:        "map keys already held keep their case",
+			Environ:     []string{"LABELS_HOST=h", "LABELS_TEAM_NAME=core", "LABELS_ENV=prod"},
+			Dest:        &envTestConfig{Labels: map[string]string{"host": "", "teamName": "old"}},
+			ExpectedVal: &envTestConfig{Labels: map[string]string{"host": "h", "teamName": "core", "ENV": "prod"}},
+		},
+		{
+			Name:        "joined slices",
+			Environ:     []string{"HOSTS=a,b,c"},
+			EnvOpts:     []Option{EnvSliceSeparator(",")},
+			Dest:        &envTestConfig{},
+			ExpectedVal: &envTestConfig{Hosts: []string{"a", "b", "c"}},
+		},
+		{
+			Name:        "parse errors name the variable and leave dest untouched",
+			Environ:     []string{"DEBUG=maybe", "SERVER_PORT=99999999999999999999", "MAX_CONNS=-1", "RATIO=0.1"},
+			Dest:        &envTestConfig{},
+			ExpectedVal: &envTestConfig{},
+			ExpErrKeys:  []string{"DEBUG", "MAX_CONNS", "SERVER_PORT"},
+		},
+		{
+			Name:        "missing required fields",
+			Environ:     []string{"SERVER_PORT=1"},
+			Dest:        &envTestRequired{},
+			ExpectedVal: &envTestRequired{},
+			ExpErrKeys:  []string{"NAME"},
+		},
+		{
+			Name:        "required fields of items and pointers only count when something was found for them",
+			Environ:     []string{"APP_HOSTS_0_NAME=h", "APP_HOSTS_0_SERVER_PORT=1", "APP_MUST_NAME=m", "APP_MUST_SERVER_PORT=2"},
+			EnvOpts:     []Option{EnvPrefix("app")},
+			Dest:        &envTestOptional{},
+			ExpectedVal: &envTestOptional{Hosts: []envTestRequired{{Name: "h", Server: envTestServer{Port: 1}}}, Must: &envTestRequired{Name: "m", Server: envTestServer{Port: 2}}},
+		},
+		{
+			Name:        "items and pointers found without their required fields",
+			Environ:     []string{"APP_HOSTS_0_SERVER_PORT=1", "APP_OPT_NAME=o", "APP_OPTIONAL=1"},
+			EnvOpts:     []Option{EnvPrefix("app")},
+			Dest:        &envTestOptional{},
+			ExpectedVal: &envTestOptional{},
+			ExpErrKeys:  []string{"APP_HOSTS_0_NAME", "APP_MUST", "APP_MUST_NAME", "APP_MUST_SERVER", "APP_OPT_SERVER"},
+		},
+	}
+
+	for _, curTest := range testSet {
+		t.Run(curTest.Name, func(t *testing.T) {
+			if curTest.SkipTest {
+				t.Skipf("skipped '%s' due to SkipTest being set", curTest.Name)
+			}
+
+			err := FromEnv(curTest.Dest, append(curTest.EnvOpts, EnvSource(curTest.Environ))...)
+
+			if errKeys := keyErrorKeys(t, err); !reflect.DeepEqual(errKeys, curTest.ExpErrKeys) {
+				t.Errorf("failed keys not as expected\nHave: %v\nWant: %v\nError: %v", errKeys, curTest.ExpErrKeys, err)
+			}
+
+			if !reflect.DeepEqual(curTest.Dest, curTest.ExpectedVal) {
+				t.Errorf("loaded value not the same as the expected value\nHave: %+v\nWant: %+v", curTest.Dest, curTest.ExpectedVal)
+			}
+		})
+	}
+}
+
+func Test_EnvRoundTrip(t *testing.T) {
+	testStruct := envTestConfig{
+		Server: envTestServer{Host: "localhost", Port: 8080},
+		Hosts:  []string{"a", "b"},
+		Labels: map[string]string{"host": "h", "teamName": "core"},
+		Pair:   [2]string{"x", "y"},
+	}
+
+	for _, opts := range [][]Option{{EnvPrefix("app")}, {EnvPrefix("app"), EnvSliceSeparator(",")}} {
+		env := ToEnv(testStruct, opts...)
+		dest := envTestConfig{Labels: map[string]string{"host": "", "teamName": ""}}
+		if err := FromEnv(&dest, append(opts, EnvSource(env))...); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(dest, testStruct) {
+			t.Errorf("round trip of %q not the same as the original\nHave: %+v\nWant: %+v", env, dest, testStruct)
+		}
+	}
+}
+
+func Test_EnvRoundTripIgnoreParents(t *testing.T) {
+	var testStruct envTestFlattened
+	testStruct.Meta.ID, testStruct.Meta.Zone, testStruct.Name = "i", "z", "n"
+
+	// the fields after an ignoreparents field lose their parents too
+	env := ToEnv(testStruct)
+	if expected := []string{"ID=i", "NAME=n", "ZONE=z"}; !reflect.DeepEqual(env, expected) {
+		t.Fatalf("generated environment not as expected\nHave: %q\nWant: %q", env, expected)
+	}
+
+	var dest envTestFlattened
+	if err := FromEnv(&dest, EnvSource(env)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(dest, testStruct) {
+		t.Errorf("round trip not the same as the original\nHave: %+v\nWant: %+v", dest, testStruct)
+	}
+}
+
+func Test_FromEnvLookup(t *testing.T) {
+	lookup := func(name string) (string, bool) {
+		if name == "SERVER_HOST" {
+			return "looked-up", true
+		}
+		return "", false
+	}
+
+	var dest envTestConfig
+	if err := FromEnv(&dest, EnvLookup(lookup)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if dest.Server.Host != "looked-up" {
+		t.Errorf("value not loaded through the lookup function: %+v", dest)
+	}
+
+	if err := FromEnv(dest); err == nil {
+		t.Errorf("expected an error loading into a non-pointer")
+	}
+}
+
+// the sorted keys of every *KeyError joined in err
+func keyErrorKeys(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+
+	var ret []string
+	for _, joined := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var keyErr *KeyError
+		if !errors.As(joined, &keyErr) {
+			t.Fatalf("error is not a *KeyError: %v", joined)
+		}
+		ret = append(ret, keyErr.Key)
+	}
+	sortKeys(ret)
+
+	return ret
+}
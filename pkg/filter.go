@@ -0,0 +1,66 @@
+package struct2map
+
+type keyFilterResult uint
+
+const (
+	keyIn      keyFilterResult = iota // the key (and everything below it) is kept
+	keyPartial                        // the key itself is not kept but keys below it may be
+	keyOut                            // the key and everything below it are pruned
+)
+
+// Only keeps the keys matching any of the glob patterns, along with everything below them; patterns are matched as
+// Redact matches them (ex: Server.*, **.Debug, Tags.#)
+//
+// Patterns are evaluated while the value is walked, so structures, maps and slices no pattern can match anything
+// within are never walked at all. May be passed more than once; the patterns add up.
+func Include(patterns ...string) Option {
+	return optionFunc(func(cfg *convertConfig) {
+		cfg.includeRules = append(cfg.includeRules, patterns...)
+	})
+}
+
+// Leaves out the keys matching any of the glob patterns, along with everything below them; patterns are matched as
+// Include matches them and take precedence over it
+//
+// Excluded structures, maps and slices are never walked at all, and values MaxDepth stores whole leave out the keys
+// excluded within them. May be passed more than once; the patterns add up.
+func Exclude(patterns ...string) Option {
+	return optionFunc(func(cfg *convertConfig) {
+		cfg.excludeRules = append(cfg.excludeRules, patterns...)
+	})
+}
+
+// whether key is kept, pruned or only walked into as the Include and Exclude patterns direct
+func (cfg *convertConfig) filterKey(key string) keyFilterResult {
+	if len(cfg.includeRules) == 0 && len(cfg.excludeRules) == 0 {
+		return keyIn
+	}
+
+	for _, pattern := range cfg.excludeRules {
+		if matchKeyGlob(pattern, key) {
+			return keyOut
+		}
+	}
+
+	if len(cfg.includeRules) == 0 {
+		return keyIn
+	}
+
+	// a key is kept if a pattern matches it or any key above it, as everything below an included key is included
+	segs := splitKey(key)
+	ret := keyOut
+	for _, pattern := range cfg.includeRules {
+		patternSegs := splitKey(pattern)
+		for depth := 1; depth <= len(segs); depth++ {
+			if matchGlobSegs(patternSegs, segs[:depth]) {
+				return keyIn
+			}
+		}
+
+		if matchGlobPrefix(patternSegs, segs) {
+			ret = keyPartial
+		}
+	}
+
+	return ret
+}
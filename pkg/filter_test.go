@@ -0,0 +1,113 @@
+package struct2map
+
+import (
+	"reflect"
+	"testing"
+)
+
+type filterTestConfig struct {
+	Debug  bool              `struct2map:"Debug"`
+	Server envTestServer     `struct2map:"Server"`
+	Tags   []string          `struct2map:"Tags"`
+	Labels map[string]string `struct2map:"Labels"`
+	Nested struct {
+		Debug bool `struct2map:"Debug"`
+		Other int  `struct2map:"Other"`
+	} `struct2map:"Nested"`
+	Backup *envTestServer `struct2map:"Backup"`
+	Secret string         `struct2map:"Secret,redact"`
+}
+
+func Test_IncludeExclude(t *testing.T) {
+	testConfig := filterTestConfig{
+		Debug:  true,
+		Server: envTestServer{Host: "h", Port: 1},
+		Tags:   []string{"a", "b"},
+		Labels: map[string]string{"x": "1", "y": "2"},
+		Secret: "s",
+	}
+	testConfig.Nested.Debug = true
+	testConfig.Nested.Other = 5
+
+	testSet := []struct {
+		Name        string
+		ConvertOpts []Option
+		ExpectedMap map[string]any
+		SkipTest    bool
+	}{
+		{
+			Name:        "include subtree",
+			ConvertOpts: []Option{Include("Server.*")},
+			ExpectedMap: map[string]any{"Server.host": "h", "Server.port": 1},
+		},
+		{
+			Name:        "include anywhere and by index",
+			ConvertOpts: []Option{Include("**.Debug", "Tags.#")},
+			ExpectedMap: map[string]any{"Debug": true, "Nested.Debug": true, "Tags.0": "a", "Tags.1": "b"},
+		},
+		{
+			Name:        "including a container includes everything within it",
+			ConvertOpts: []Option{Include("Labels", "Backup", "Secret")},
+			ExpectedMap: map[string]any{"Labels.x": "1", "Labels.y": "2", "Backup": nil, "Secret": DEFAULT_REDACT_MASK},
+		},
+		{
+			Name:        "exclude",
+			ConvertOpts: []Option{Exclude("Server", "Tags.#", "**.Debug", "Labels.x", "Secret")},
+			ExpectedMap: map[string]any{"Labels.y": "2", "Nested.Other": 5, "Backup": nil},
+		},
+		{
+			Name:        "exclude wins over include",
+			ConvertOpts: []Option{Include("Nested"), Exclude("Nested.Debug")},
+			ExpectedMap: map[string]any{"Nested.Other": 5},
+		},
+		{
+			Name:        "filters apply within values stored whole at MaxDepth",
+			ConvertOpts: []Option{MaxDepth(1), Exclude("Server.port", "Labels.x"), Include("Server", "Labels", "Nested.Other", "Tags")},
+			ExpectedMap: map[string]any{
+				"Server": map[string]any{"host": "h"},
+				"Labels": map[string]any{"y": "2"},
+				"Nested": map[string]any{"Other": 5},
+				"Tags":   testConfig.Tags,
+			},
+		},
+		{
+			Name:        "slices kept whole are not indexes",
+			ConvertOpts: []Option{Include("Tags.#"), KeepSlices()},
+			ExpectedMap: map[string]any{},
+		},
+	}
+
+	for _, curTest := range testSet {
+		t.Run(curTest.Name, func(t *testing.T) {
+			if curTest.SkipTest {
+				t.Skipf("skipped '%s' due to SkipTest being set", curTest.Name)
+			}
+
+			genMap := Convert(testConfig, curTest.ConvertOpts...)
+			if !reflect.DeepEqual(genMap, curTest.ExpectedMap) {
+				t.Errorf("generated map not the same as the expected map\nHave: %v\nWant: %v", genMap, curTest.ExpectedMap)
+			}
+		})
+	}
+}
+
+// a recursive type would never finish walking if pruned subtrees were walked
+type filterTestNode struct {
+	Name string          `struct2map:"Name"`
+	Next *filterTestNode `struct2map:"Next"`
+}
+
+func Test_ExcludePrunes(t *testing.T) {
+	loop := &filterTestNode{Name: "a"}
+	loop.Next = loop
+
+	genMap := Convert(loop, Exclude("Next"))
+	if !reflect.DeepEqual(genMap, map[string]any{"Name": "a"}) {
+		t.Errorf("unexpected map: %v", genMap)
+	}
+
+	genMap = Convert(loop, Include("Name"))
+	if !reflect.DeepEqual(genMap, map[string]any{"Name": "a"}) {
+		t.Errorf("unexpected map: %v", genMap)
+	}
+}
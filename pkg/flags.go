@@ -0,0 +1,185 @@
+package struct2map
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/newodahs/struct2map/internal"
+)
+
+// Takes a pointer to a structure and registers a flag on fs for every value it can hold, named after its flattened
+// key (ex: -server.port); allows passing of various options (see StructConvertOpts constants)
+//
+// Each flag defaults to the current value of its field (or its default tag option, if zero; the default is only shown,
+// not written to the structure) and takes its usage text from the field's desc tag
+// (ex: `desc:"port to listen on"`). Parsing fs writes every flag set straight back into the structure, parsed into
+// the field's type as Patch parses strings. Flags are registered as follows:
+//   - plain values as a single flag; bools may be passed without a value (ex: -debug)
+//   - slices of plain values as a repeatable flag; the first use replaces the default and every further use appends
+//     (ex: -tags a -tags b)
+//   - arrays of plain values as a repeatable flag setting one index per use
+//   - maps of plain values as a repeatable key=value flag adding to the map (ex: -labels team=core)
+//   - structures (and pointers to them) are walked into; nil pointers are only allocated once one of their flags is set
+//
+// Slices and maps holding anything else cannot be expressed as flags and are not registered.
+//
+// Returns: nil on success or an error if objPtr is not a non-nil pointer to a structure
+func BindFlags(fs *flag.FlagSet, objPtr any, opts ...Option) error {
+	cfg := newConvertConfig(opts...)
+
+	target, err := targetValue("BindFlags", objPtr)
+	if err != nil {
+		return err
+	}
+	if target.Kind() != reflect.Struct {
+		return fmt.Errorf("struct2map: BindFlags requires a pointer to a structure, got %T", objPtr)
+	}
+
+	// fields left at their zero value show their defaults as the flag defaults; the structure is only ever written to
+	// by parsing
+	if err := defaultsErr(target.Type()); err != nil {
+		return err
+	}
+	withDefaults, _ := applyDefaults(cfg, target, nil, nil)
+
+	binder := &flagBinder{cfg: cfg, fs: fs, target: target, inProgress: make(map[reflect.Type]bool)}
+	binder.walk(target.Type(), withDefaults, nil, "")
+
+	return nil
+}
+
+type flagBinder struct {
+	cfg        *convertConfig
+	fs         *flag.FlagSet
+	target     reflect.Value
+	inProgress map[reflect.Type]bool // guards against recursive types
+}
+
+// registers flags for everything t can hold at segs; cur is the current value at segs, if there is one
+func (b *flagBinder) walk(t reflect.Type, cur reflect.Value, segs []string, usage string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		if cur.IsValid() && !cur.IsNil() {
+			cur = cur.Elem()
+		} else {
+			cur = reflect.Value{}
+		}
+	}
+
+	value := &flagValue{cfg: b.cfg, target: b.target, segs: segs}
+	switch {
+	case isLeafType(t):
+		value.isBool = t.Kind() == reflect.Bool
+		if cur.IsValid() {
+			value.text = internal.ConvertValueToString(cur)
+		}
+	case t.Kind() == reflect.Struct:
+		if b.inProgress[t] {
+			return
+		}
+		b.inProgress[t] = true
+		defer delete(b.inProgress, t)
+
+		parentSegs := segs
+		for pos := 0; pos < t.NumField(); pos++ {
+			field := t.Field(pos)
+			tag := parseFieldTag(b.cfg, field)
+			if tag.skip || tag.unexported {
+				continue
+			}
+
+			// as in structToMap, ignoring parents drops the prefix for this field and every field after it
+			if tag.ignoreParents {
+				parentSegs = nil
+			}
+			childSegs := appendSeg(parentSegs, fieldKeyName(b.cfg, tag))
+
+			var fieldCur reflect.Value
+			if cur.IsValid() {
+				fieldCur = cur.Field(pos)
+			}
+			b.walk(field.Type, fieldCur, childSegs, field.Tag.Get(internal.STRUCT_MAP_DESC_TAGNAME))
+		}
+		return
+	case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && isLeafType(t.Elem()):
+		value.isSlice = t.Kind() == reflect.Slice
+		value.isArray = t.Kind() == reflect.Array
+		if cur.IsValid() {
+			items, _ := formatSliceValues(b.cfg, cur.Interface())
+			value.text = strings.Join(items, ",")
+		}
+	case t.Kind() == reflect.Map && isLeafType(t.Key()) && isLeafType(t.Elem()):
+		value.isMap = true
+		if cur.IsValid() {
+			items := make([]string, 0, cur.Len())
+			mapItr := cur.MapRange()
+			for mapItr.Next() {
+				items = append(items, fmt.Sprintf("%s=%s", mapSubKey(b.cfg, mapItr.Key()), internal.ConvertValueToString(mapItr.Value())))
+			}
+			sort.Strings(items)
+			value.text = strings.Join(items, ",")
+		}
+	default:
+		return
+	}
+
+	b.fs.Var(value, joinKey(segs), usage)
+}
+
+// a flag.Value writing back into the bound structure at segs
+type flagValue struct {
+	cfg    *convertConfig
+	target reflect.Value // the root structure every flag writes back into
+	segs   []string
+
+	isBool  bool
+	isSlice bool
+	isArray bool
+	isMap   bool
+
+	text  string   // the value as last set (or the default)
+	items []string // every value set so far for the repeatable flags
+}
+
+func (f *flagValue) String() string {
+	return f.text
+}
+
+func (f *flagValue) IsBoolFlag() bool {
+	return f.isBool
+}
+
+func (f *flagValue) Set(str string) error {
+	segs, val := f.segs, any(str)
+	switch {
+	case f.isSlice:
+		val = append([]string(nil), append(f.items, str)...)
+	case f.isArray:
+		segs = appendSeg(f.segs, strconv.Itoa(len(f.items)))
+	case f.isMap:
+		mapKey, mapVal, found := strings.Cut(str, "=")
+		if !found {
+			return fmt.Errorf("expected key=value")
+		}
+		segs, val = appendSeg(f.segs, mapKey), mapVal
+	}
+
+	newVal, err := assignPath(f.cfg, f.target, segs, val, true)
+	if err != nil {
+		return err
+	}
+	f.target.Set(newVal)
+
+	if f.isSlice || f.isArray || f.isMap {
+		f.items = append(f.items, str)
+		f.text = strings.Join(f.items, ",")
+	} else {
+		f.text = str
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package struct2map
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"testing"
+)
+
+type flagTestConfig struct {
+	Debug   bool              `struct2map:"debug" desc:"enable debug logging"`
+	Server  envTestServer     `struct2map:"server"`
+	Backup  *envTestServer    `struct2map:"backup"`
+	Tags    []string          `struct2map:"tags" desc:"tags to apply"`
+	Ports   [2]int            `struct2map:"ports"`
+	Labels  map[string]string `struct2map:"labels"`
+	Nodes   []envTestServer   `struct2map:"nodes"`
+	Skipped string            `struct2map:"-"`
+}
+
+func Test_BindFlags(t *testing.T) {
+	testSet := []struct {
+		Name        string
+		Args        []string
+		ExpectedVal flagTestConfig
+		ExpectErr   bool
+		SkipTest    bool
+	}{
+		{
+			Name:        "defaults kept when nothing is passed",
+			ExpectedVal: flagTestConfig{Server: envTestServer{Host: "localhost", Port: 80}, Tags: []string{"default"}, Labels: map[string]string{"env": "dev"}},
+		},
+		{
+			Name: "every kind of flag",
+			Args: []string{
+				"-debug", "--server.port=8080", "-backup.host", "b", "-tags", "a", "-tags=b",
+				"-ports", "1", "-ports", "2", "-labels", "team=core",
+			},
+			ExpectedVal: flagTestConfig{
+				Debug:  true,
+				Server: envTestServer{Host: "localhost", Port: 8080},
+				Backup: &envTestServer{Host: "b"},
+				Tags:   []string{"a", "b"},
+				Ports:  [2]int{1, 2},
+				Labels: map[string]string{"env": "dev", "team": "core"},
+			},
+		},
+		{
+			Name:      "bad value",
+			Args:      []string{"-server.port=eighty"},
+			ExpectErr: true,
+		},
+		{
+			Name:      "bad map value",
+			Args:      []string{"-labels", "team"},
+			ExpectErr: true,
+		},
+		{
+			Name:      "too many array values",
+			Args:      []string{"-ports=1", "-ports=2", "-ports=3"},
+			ExpectErr: true,
+		},
+		{
+			Name:      "structures in slices are not registered",
+			Args:      []string{"-nodes.0.host=x"},
+			ExpectErr: true,
+		},
+	}
+
+	for _, curTest := range testSet {
+		t.Run(curTest.Name, func(t *testing.T) {
+			if curTest.SkipTest {
+				t.Skipf("skipped '%s' due to SkipTest being set", curTest.Name)
+			}
+
+			testConfig := flagTestConfig{Server: envTestServer{Host: "localhost", Port: 80}, Tags: []string{"default"}, Labels: map[string]string{"env": "dev"}}
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			if err := BindFlags(fs, &testConfig); err != nil {
+				t.Fatalf("unexpected error binding: %v", err)
+			}
+
+			err := fs.Parse(curTest.Args)
+			if curTest.ExpectErr {
+				if err == nil {
+					t.Errorf("expected an error parsing %v", curTest.Args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error parsing: %v", err)
+			}
+
+			if !reflect.DeepEqual(testConfig, curTest.ExpectedVal) {
+				t.Errorf("parsed value not the same as the expected value\nHave: %+v\nWant: %+v", testConfig, curTest.ExpectedVal)
+			}
+		})
+	}
+}
+
+func Test_BindFlagsIgnoreParents(t *testing.T) {
+	var testConfig envTestFlattened
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if err := BindFlags(fs, &testConfig); err != nil {
+		t.Fatalf("unexpected error binding: %v", err)
+	}
+
+	// the fields after an ignoreparents field lose their parents too
+	if err := fs.Parse([]string{"-id=i", "-zone=z", "-name=n"}); err != nil {
+		t.Fatalf("unexpected error parsing: %v", err)
+	}
+
+	var expected envTestFlattened
+	expected.Meta.ID, expected.Meta.Zone, expected.Name = "i", "z", "n"
+	if !reflect.DeepEqual(testConfig, expected) {
+		t.Errorf("parsed value not the same as the expected value\nHave: %+v\nWant: %+v", testConfig, expected)
+	}
+}
+
+func Test_BindFlagsUsage(t *testing.T) {
+	testConfig := flagTestConfig{Server: envTestServer{Port: 80}, Tags: []string{"x", "y"}}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := BindFlags(fs, &testConfig); err != nil {
+		t.Fatalf("unexpected error binding: %v", err)
+	}
+
+	expected := map[string][2]string{
+		"debug":       {"false", "enable debug logging"},
+		"server.port": {"80", ""},
+		"server.host": {"", ""},
+		"backup.host": {"", ""},
+		"backup.port": {"", ""},
+		"tags":        {"x,y", "tags to apply"},
+		"ports":       {"0,0", ""},
+		"labels":      {"", ""},
+	}
+
+	have := make(map[string][2]string)
+	fs.VisitAll(func(f *flag.Flag) {
+		have[f.Name] = [2]string{f.DefValue, f.Usage}
+	})
+	if !reflect.DeepEqual(have, expected) {
+		t.Errorf("registered flags not the same as the expected flags\nHave: %v\nWant: %v", have, expected)
+	}
+
+	if err := BindFlags(fs, testConfig); err == nil {
+		t.Errorf("expected an error binding a non-pointer")
+	}
+}
@@ -0,0 +1,27 @@
+package struct2map
+
+import (
+	"reflect"
+
+	"github.com/newodahs/struct2map/internal"
+)
+
+// the string form of a single flattened value as used by the text based encoders (see the string format options)
+func formatValue(cfg *convertConfig, val any) string {
+	return internal.ConvertAnyToStringFormat(val, cfg.stringFormat)
+}
+
+// the string form of every item of a slice (or array) value; ok is false if val is not a slice or array
+func formatSliceValues(cfg *convertConfig, val any) (ret []string, ok bool) {
+	valOf := reflect.ValueOf(val)
+	if valOf.Kind() != reflect.Slice && valOf.Kind() != reflect.Array {
+		return nil, false
+	}
+
+	ret = make([]string, valOf.Len())
+	for idx := range ret {
+		ret[idx] = internal.ConvertValueToStringFormat(valOf.Index(idx), cfg.stringFormat)
+	}
+
+	return ret, true
+}
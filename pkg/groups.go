@@ -0,0 +1,33 @@
+package struct2map
+
+// Only outputs the fields in any of the given groups, as named by the groups tag option
+// (ex: `struct2map:"email,groups=internal|admin"`); without this option every field is output regardless of its groups
+//
+// Fields that do not name groups themselves are in the groups of the field containing them (so a nested structure's
+// fields follow the field holding the structure) and fields with no groups at all, named or inherited, are in every
+// group. A field naming its own groups replaces the inherited ones, but is only ever reached if the fields containing
+// it are output as well. Values MaxDepth stores whole leave out the fields hidden within them too.
+//
+// May be passed more than once; the groups add up.
+func Groups(groups ...string) Option {
+	return optionFunc(func(cfg *convertConfig) {
+		cfg.activeGroups = append(cfg.activeGroups, groups...)
+	})
+}
+
+// true if a field in the given groups is output
+func (cfg *convertConfig) inActiveGroup(groups []string) bool {
+	if len(cfg.activeGroups) == 0 || len(groups) == 0 {
+		return true
+	}
+
+	for _, group := range groups {
+		for _, active := range cfg.activeGroups {
+			if group == active {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,86 @@
+package struct2map
+
+import (
+	"reflect"
+	"testing"
+)
+
+type groupsTestBilling struct {
+	Plan string `struct2map:"plan"`
+	Card string `struct2map:"card,groups=admin"`
+}
+
+type groupsTestUser struct {
+	ID      int                          `struct2map:"id"`
+	Name    string                       `struct2map:"name,groups=public|internal|admin"`
+	Email   string                       `struct2map:"email,groups=internal|admin"`
+	Billing groupsTestBilling            `struct2map:"billing,groups=internal|admin"`
+	Notes   map[string]groupsTestBilling `struct2map:"notes,groups=admin"`
+	Friends []groupsTestBilling          `struct2map:"friends,groups=public"`
+}
+
+func Test_Groups(t *testing.T) {
+	testUser := groupsTestUser{
+		ID:      1,
+		Name:    "n",
+		Email:   "e",
+		Billing: groupsTestBilling{Plan: "pro", Card: "4242"},
+		Friends: []groupsTestBilling{{Plan: "free", Card: "1111"}},
+	}
+
+	testSet := []struct {
+		Name        string
+		ConvertOpts []Option
+		ExpectedMap map[string]any
+		SkipTest    bool
+	}{
+		{
+			Name: "no groups selected outputs everything",
+			ExpectedMap: map[string]any{
+				"id": 1, "name": "n", "email": "e", "billing.plan": "pro", "billing.card": "4242",
+				"friends.0.plan": "free", "friends.0.card": "1111",
+			},
+		},
+		{
+			Name:        "public",
+			ConvertOpts: []Option{Groups("public")},
+			ExpectedMap: map[string]any{"id": 1, "name": "n", "friends.0.plan": "free"},
+		},
+		{
+			Name:        "internal inherits into nested structures",
+			ConvertOpts: []Option{Groups("internal")},
+			ExpectedMap: map[string]any{"id": 1, "name": "n", "email": "e", "billing.plan": "pro"},
+		},
+		{
+			Name:        "several groups",
+			ConvertOpts: []Option{Groups("public"), Groups("admin")},
+			ExpectedMap: map[string]any{
+				"id": 1, "name": "n", "email": "e", "billing.plan": "pro", "billing.card": "4242",
+				"friends.0.plan": "free", "friends.0.card": "1111",
+			},
+		},
+		{
+			Name:        "values stored whole at MaxDepth hide fields too",
+			ConvertOpts: []Option{Groups("internal"), MaxDepth(1)},
+			ExpectedMap: map[string]any{"id": 1, "name": "n", "email": "e", "billing": map[string]any{"plan": "pro"}},
+		},
+		{
+			Name:        "unknown group",
+			ConvertOpts: []Option{Groups("nobody")},
+			ExpectedMap: map[string]any{"id": 1},
+		},
+	}
+
+	for _, curTest := range testSet {
+		t.Run(curTest.Name, func(t *testing.T) {
+			if curTest.SkipTest {
+				t.Skipf("skipped '%s' due to SkipTest being set", curTest.Name)
+			}
+
+			genMap := Convert(testUser, curTest.ConvertOpts...)
+			if !reflect.DeepEqual(genMap, curTest.ExpectedMap) {
+				t.Errorf("generated map not the same as the expected map\nHave: %v\nWant: %v", genMap, curTest.ExpectedMap)
+			}
+		})
+	}
+}
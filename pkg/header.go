@@ -0,0 +1,163 @@
+package struct2map
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+var ErrInvalidHeader = errors.New("invalid characters for an HTTP header field")
+
+// Takes a structure (or any value Convert accepts) and encodes it as HTTP headers named after the flattened keys;
+// allows passing of various options (see StructConvertOpts constants)
+//
+// Header names are prefix followed by the segments of the flattened key, each converted to kebab case and the whole
+// name canonicalized (ex: the prefix "X-Meta" turns Server.maxConns into X-Meta-Server-Max-Conns). Slices of plain
+// values are encoded as one header value per item and nil values as an empty value; every other value is formatted
+// as ToEnv formats it.
+//
+// Names must be valid HTTP tokens and values must not hold control characters (ex: CR, LF or NUL), so a value can
+// never be used to inject further headers.
+//
+// Returns: the headers or an error joining a *KeyError for every key with an invalid name or value
+func ToHeader(obj any, prefix string, opts ...Option) (http.Header, error) {
+	cfg := newConvertConfig(opts...)
+	cfg.keepSlices = true
+
+	flat := convertToMap(cfg, obj)
+	if flat == nil {
+		return nil, errors.New("struct2map: ToHeader requires a structure, map, slice or array")
+	}
+
+	keys := make([]string, 0, len(flat))
+	for k := range flat {
+		keys = append(keys, k)
+	}
+	sortKeys(keys)
+
+	ret := make(http.Header, len(keys))
+	var errs []error
+	for _, k := range keys {
+		name := headerName(prefix, splitKey(k))
+		if !validHeaderName(name) {
+			errs = append(errs, &KeyError{Key: k, Err: ErrInvalidHeader})
+			continue
+		}
+
+		items, ok := formatSliceValues(cfg, flat[k])
+		if !ok {
+			items = []string{formatValue(cfg, flat[k])}
+		}
+
+		for _, item := range items {
+			if !validHeaderValue(item) {
+				errs = append(errs, &KeyError{Key: k, Err: ErrInvalidHeader})
+				break
+			}
+			ret.Add(name, item)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return ret, nil
+}
+
+// builds the canonical header name for the given key segments
+func headerName(prefix string, segs []string) string {
+	parts := make([]string, 0, len(segs)+1)
+	if prefix != "" {
+		parts = append(parts, strcase.ToKebab(prefix))
+	}
+	for _, seg := range segs {
+		parts = append(parts, strcase.ToKebab(seg))
+	}
+
+	return http.CanonicalHeaderKey(strings.Join(parts, "-"))
+}
+
+// true if name is a token as RFC 9110 defines it
+func validHeaderName(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for idx := 0; idx < len(name); idx++ {
+		char := name[idx]
+		isAlnum := (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9')
+		if !isAlnum && strings.IndexByte("!#$%&'*+-.^_`|~", char) < 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// true if value holds no control characters other than horizontal tab
+func validHeaderValue(value string) bool {
+	for idx := 0; idx < len(value); idx++ {
+		if char := value[idx]; (char < 0x20 && char != '\t') || char == 0x7f {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Populates the structure pointed to by dest from HTTP headers named the same way ToHeader names them; allows passing
+// of various options (see StructConvertOpts constants)
+//
+// Header names are matched case insensitively and need not be canonical. Slices of plain values are loaded from every
+// value of their header (values are not split on commas) and slices of anything else from indexed headers
+// (ex: X-Meta-Endpoints-0-Host). Map entries holding plain values are loaded from every header below the map's name,
+// with the rest of the canonical name as the map key (ex: X-Meta-Labels-Team sets the key Team), unless the map
+// already holds a key named that way (ex: team), which is reused. Fields tagged with the required option must have
+// at least one header found for them; within slice items and pointers to structures that is only checked once anything
+// is found for the item or pointer, or the pointer is tagged required itself.
+//
+// Returns: nil on success or an error joining a *KeyError, naming the offending header, for every value that could not
+// be parsed into its field and every missing required field; on error dest is left untouched
+func FromHeader(header http.Header, prefix string, dest any, opts ...Option) error {
+	cfg := newConvertConfig(opts...)
+
+	target, err := targetValue("FromHeader", dest)
+	if err != nil {
+		return err
+	}
+
+	// headers built by hand may hold names that are not canonical; canonicalize them so every lookup finds them
+	canonical := make(http.Header, len(header))
+	for name, vals := range header {
+		canonName := http.CanonicalHeaderKey(name)
+		canonical[canonName] = append(canonical[canonName], vals...)
+	}
+
+	src := nameSource{
+		lookup: func(name string) ([]string, bool) {
+			vals := canonical.Values(name)
+			return vals, len(vals) > 0
+		},
+		names:    make([]string, 0, len(canonical)),
+		foldCase: true,
+	}
+	for name := range canonical {
+		src.names = append(src.names, name)
+	}
+	sortKeys(src.names)
+
+	loader := &nameLoader{
+		cfg:        cfg,
+		src:        src,
+		nameOf:     func(segs []string) string { return headerName(prefix, segs) },
+		sep:        "-",
+		inProgress: make(map[reflect.Type]bool),
+	}
+	loader.walk(target.Type(), target, nil)
+
+	return loader.apply(target)
+}
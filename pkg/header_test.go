@@ -0,0 +1,182 @@
+package struct2map
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+type headerTestMeta struct {
+	RequestID string            `struct2map:"requestId"`
+	Server    envTestServer     `struct2map:"server"`
+	Roles     []string          `struct2map:"roles"`
+	Labels    map[string]string `struct2map:"labels"`
+	Retries   *int              `struct2map:"retries"`
+	Range     [2]int            `struct2map:"range"`
+}
+
+func Test_ToHeader(t *testing.T) {
+	testSet := []struct {
+		Name           string
+		TestStruct     any
+		Prefix         string
+		ExpectedHeader http.Header
+		ExpErrKeys     []string
+		SkipTest       bool
+	}{
+		{
+			Name: "canonical names and multiple values",
+			TestStruct: headerTestMeta{
+				RequestID: "abc-123",
+				Server:    envTestServer{Host: "localhost", Port: 8080},
+				Roles:     []string{"admin", "ops"},
+				Labels:    map[string]string{"team": "core"},
+			},
+			Prefix: "X-Meta",
+			ExpectedHeader: http.Header{
+				"X-Meta-Request-Id":  {"abc-123"},
+				"X-Meta-Server-Host": {"localhost"},
+				"X-Meta-Server-Port": {"8080"},
+				"X-Meta-Roles":       {"admin", "ops"},
+				"X-Meta-Labels-Team": {"core"},
+				"X-Meta-Retries":     {""},
+				"X-Meta-Range":       {"0", "0"},
+			},
+		},
+		{
+			Name:       "control characters in values and invalid names are rejected",
+			TestStruct: headerTestMeta{RequestID: "abc\r\nSet-Cookie: x=1", Roles: []string{"ok", "bad\x00"}, Labels: map[string]string{"a:b": "v"}},
+			Prefix:     "X-Meta",
+			ExpErrKeys: []string{"labels.a:b", "requestId", "roles"},
+		},
+		{
+			Name:       "not a structure",
+			TestStruct: 1,
+			ExpErrKeys: nil,
+		},
+	}
+
+	for _, curTest := range testSet {
+		t.Run(curTest.Name, func(t *testing.T) {
+			if curTest.SkipTest {
+				t.Skipf("skipped '%s' due to SkipTest being set", curTest.Name)
+			}
+
+			genHeader, err := ToHeader(curTest.TestStruct, curTest.Prefix)
+			if curTest.ExpectedHeader == nil && curTest.ExpErrKeys == nil {
+				if err == nil {
+					t.Errorf("expected an error for %T", curTest.TestStruct)
+				}
+				return
+			}
+
+			if errKeys := keyErrorKeys(t, err); !reflect.DeepEqual(errKeys, curTest.ExpErrKeys) {
+				t.Errorf("failed keys not as expected\nHave: %v\nWant: %v\nError: %v", errKeys, curTest.ExpErrKeys, err)
+			}
+			if !reflect.DeepEqual(genHeader, curTest.ExpectedHeader) {
+				t.Errorf("generated header not the same as the expected header\nHave: %v\nWant: %v", genHeader, curTest.ExpectedHeader)
+			}
+		})
+	}
+}
+
+func Test_FromHeader(t *testing.T) {
+	retries := 3
+
+	testSet := []struct {
+		Name        string
+		Header      http.Header
+		Dest        any
+		ExpectedVal any
+		ExpErrKeys  []string
+		SkipTest    bool
+	}{
+		{
+			Name: "round trip of canonical names",
+			Header: http.Header{
+				"X-Meta-Request-Id":  {"abc-123"},
+				"X-Meta-Server-Port": {"8080"},
+				"X-Meta-Roles":       {"admin", "ops"},
+				"X-Meta-Labels-Team": {"core"},
+				"X-Meta-Retries":     {"3"},
+				"X-Other":            {"ignored"},
+			},
+			Dest: &headerTestMeta{},
+			ExpectedVal: &headerTestMeta{
+				RequestID: "abc-123",
+				Server:    envTestServer{Port: 8080},
+				Roles:     []string{"admin", "ops"},
+				Labels:    map[string]string{"Team": "core"},
+				Retries:   &retries,
+			},
+		},
+		{
+			Name:        "names are matched case insensitively",
+			Header:      http.Header{"x-meta-server-host": {"h"}, "x-meta-labels-env": {"prod"}},
+			Dest:        &headerTestMeta{},
+			ExpectedVal: &headerTestMeta{Server: envTestServer{Host: "h"}, Labels: map[string]string{"Env": "prod"}},
+		},
+		{
+			Name:        "map keys already held keep their case",
+			Header:      http.Header{"X-Meta-Labels-Team-Name": {"core"}, "x-meta-labels-env": {"prod"}},
+			Dest:        &headerTestMeta{Labels: map[string]string{"teamName": "old"}},
+			ExpectedVal: &headerTestMeta{Labels: map[string]string{"teamName": "core", "Env": "prod"}},
+		},
+		{
+			Name: "required fields of absent pointers are not missing",
+			Header: http.Header{
+				"X-Meta-Must-Name": {"m"}, "X-Meta-Must-Server-Port": {"1"},
+				"X-Meta-Hosts-0-Name": {"h"}, "X-Meta-Hosts-0-Server-Port": {"2"},
+			},
+			Dest: &envTestOptional{},
+			ExpectedVal: &envTestOptional{
+				Hosts: []envTestRequired{{Name: "h", Server: envTestServer{Port: 2}}},
+				Must:  &envTestRequired{Name: "m", Server: envTestServer{Port: 1}},
+			},
+		},
+		{
+			Name:        "parse errors name the header",
+			Header:      http.Header{"X-Meta-Server-Port": {"eighty"}, "X-Meta-Retries": {"x"}},
+			Dest:        &headerTestMeta{},
+			ExpectedVal: &headerTestMeta{},
+			ExpErrKeys:  []string{"X-Meta-Retries", "X-Meta-Server-Port"},
+		},
+	}
+
+	for _, curTest := range testSet {
+		t.Run(curTest.Name, func(t *testing.T) {
+			if curTest.SkipTest {
+				t.Skipf("skipped '%s' due to SkipTest being set", curTest.Name)
+			}
+
+			err := FromHeader(curTest.Header, "X-Meta", curTest.Dest)
+			if errKeys := keyErrorKeys(t, err); !reflect.DeepEqual(errKeys, curTest.ExpErrKeys) {
+				t.Errorf("failed keys not as expected\nHave: %v\nWant: %v\nError: %v", errKeys, curTest.ExpErrKeys, err)
+			}
+			if !reflect.DeepEqual(curTest.Dest, curTest.ExpectedVal) {
+				t.Errorf("loaded value not the same as the expected value\nHave: %+v\nWant: %+v", curTest.Dest, curTest.ExpectedVal)
+			}
+		})
+	}
+}
+
+func Test_HeaderRoundTrip(t *testing.T) {
+	testStruct := headerTestMeta{
+		RequestID: "abc-123",
+		Labels:    map[string]string{"teamName": "core", "ENV": "prod", "region": "eu"},
+		Range:     [2]int{1, 5},
+	}
+
+	header, err := ToHeader(testStruct, "X-Meta")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dest := headerTestMeta{Labels: map[string]string{"teamName": "", "ENV": "", "region": ""}}
+	if err := FromHeader(header, "X-Meta", &dest); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(dest, testStruct) {
+		t.Errorf("round trip not the same as the original\nHave: %+v\nWant: %+v", dest, testStruct)
+	}
+}
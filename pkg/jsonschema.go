@@ -0,0 +1,252 @@
+package struct2map
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const JSON_SCHEMA_DIALECT = "https://json-schema.org/draft/2020-12/schema"
+
+// Takes a structure type (either a reflect.Type or a value of the type, zero or not) and produces a JSON Schema
+// (draft 2020-12) describing the flat map ConvertStruct (or Convert) outputs for it; allows passing of various options
+// (see StructConvertOpts constants and the other Option returning functions) which shape the keys as they would for
+// the conversion
+//
+// The keys are those Schema lists: exact keys become properties while keys with wildcards become patternProperties
+// (ex: Tags.# becomes ^Tags\.[0-9]+$). Go kinds are mapped to JSON types, with the range of the integer kinds up to
+// 32 bits as minimum and maximum. Keys of fields tagged required are listed as required; those below a pointer are
+// required unless the pointer's own key is present instead (as a nil pointer stores it), through allOf and anyOf. The
+// following field tags add constraints:
+//   - desc: the description
+//   - enum: the allowed values, separated by | (ex: enum:"debug|info|warn"); each parsed as the field's type
+//   - min and max: the minimum and maximum for numbers or the minLength and maxLength for strings
+//
+// Returns: the JSON Schema document or an error (ex: not a structure type; a tag value that cannot be parsed as the field's type)
+func JSONSchema(typeOrValue any, opts ...Option) ([]byte, error) {
+	specs := Schema(typeOrValue, opts...)
+	if specs == nil {
+		return nil, fmt.Errorf("struct2map: cannot produce a schema for %v", typeOrValue)
+	}
+
+	cfg := newConvertConfig(opts...)
+	properties := map[string]any{}
+	patterns := map[string]any{}
+	required := []string{}
+	var requiredAny []any
+	var errs []error
+
+	// the keys a nil pointer is stored at in place of the keys below it
+	nilKeys := map[string]bool{}
+	for _, spec := range specs {
+		if !spec.Nullable || isSchemaPattern(spec.Key) {
+			continue
+		}
+		for _, below := range specs {
+			// an interface is stored at its own key by ConvertStruct, whatever Convert flattens out of it
+			if below.Key != spec.Key && keyHasPrefix(below.Key, spec.Key) && below.Type.Kind() != reflect.Interface {
+				nilKeys[spec.Key] = true
+				break
+			}
+		}
+	}
+
+	for _, spec := range specs {
+		if spec.Redacted && cfg.redactMode == REDACT_DROP {
+			continue
+		}
+
+		prop, err := jsonSchemaProperty(spec)
+		if err != nil {
+			errs = append(errs, &KeyError{Key: spec.Key, Err: err})
+			continue
+		}
+
+		if !isSchemaPattern(spec.Key) {
+			properties[spec.Key] = prop
+			if !spec.Required || nilKeys[spec.Key] {
+				continue
+			}
+
+			// a required key below a pointer is only output when the pointer is not nil
+			segs := splitKey(spec.Key)
+			alternatives := []any{map[string]any{"required": []string{spec.Key}}}
+			for idx := len(segs) - 1; idx > 0; idx-- {
+				if nilKeys[joinKey(segs[:idx])] {
+					alternatives = append(alternatives, map[string]any{"required": []string{joinKey(segs[:idx])}})
+				}
+			}
+			if len(alternatives) == 1 {
+				required = append(required, spec.Key)
+			} else {
+				requiredAny = append(requiredAny, map[string]any{"anyOf": alternatives})
+			}
+			continue
+		}
+		patterns[schemaKeyRegexp(spec.Key)] = prop
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	doc := map[string]any{
+		"$schema":              JSON_SCHEMA_DIALECT,
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(patterns) > 0 {
+		doc["patternProperties"] = patterns
+	}
+	if len(required) > 0 {
+		doc["required"] = required
+	}
+	if len(requiredAny) > 0 {
+		doc["allOf"] = requiredAny
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// the JSON Schema for the value of a single key
+func jsonSchemaProperty(spec KeySpec) (map[string]any, error) {
+	prop := map[string]any{}
+	if spec.Desc != "" {
+		prop["description"] = spec.Desc
+	}
+
+	if spec.Recursive {
+		return prop, nil // anything below a recursive structure; its keys are not spelled out
+	}
+
+	if spec.Redacted {
+		prop["type"] = "string" // the mask (or hash) replacing the value; the field's own constraints no longer apply
+		return prop, nil
+	}
+
+	jsonType := jsonSchemaType(spec.Type, prop)
+	if jsonType != "" {
+		prop["type"] = jsonType
+		if spec.Nullable {
+			prop["type"] = []string{jsonType, "null"}
+		}
+	}
+
+	if spec.Enum != nil {
+		enum := make([]any, len(spec.Enum))
+		for idx, item := range spec.Enum {
+			parsed, err := parseString(spec.Type, item)
+			if err != nil {
+				return nil, fmt.Errorf("enum value %q: %w", item, err)
+			}
+			enum[idx] = parsed.Interface()
+		}
+		prop["enum"] = enum
+	}
+
+	for _, bound := range []struct {
+		tagVal   string
+		numName  string
+		sizeName string
+	}{
+		{spec.Min, "minimum", "minLength"},
+		{spec.Max, "maximum", "maxLength"},
+	} {
+		if bound.tagVal == "" {
+			continue
+		}
+
+		switch jsonType {
+		case "integer", "number":
+			num, err := strconv.ParseFloat(bound.tagVal, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: cannot parse %s %q as a number", ErrTypeMismatch, bound.numName, bound.tagVal)
+			}
+			prop[bound.numName] = num
+		case "string":
+			size, err := strconv.ParseUint(bound.tagVal, 10, 0)
+			if err != nil {
+				return nil, fmt.Errorf("%w: cannot parse %s %q as a length", ErrTypeMismatch, bound.sizeName, bound.tagVal)
+			}
+			prop[bound.sizeName] = size
+		}
+	}
+
+	return prop, nil
+}
+
+// the JSON type a Go type maps to (empty if it can be anything); adds the constraints that come with the type to prop
+func jsonSchemaType(t reflect.Type, prop map[string]any) string {
+	if t == timeType {
+		prop["format"] = "date-time"
+		return "string"
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		prop["minimum"] = -(int64(1) << (t.Bits() - 1))
+		prop["maximum"] = int64(1)<<(t.Bits()-1) - 1
+		return "integer"
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		prop["minimum"] = 0
+		prop["maximum"] = uint64(1)<<t.Bits() - 1
+		return "integer"
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		prop["minimum"] = 0
+		return "integer"
+	case reflect.Int, reflect.Int64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		items := map[string]any{}
+		if itemType := jsonSchemaType(t.Elem(), items); itemType != "" {
+			items["type"] = itemType
+		}
+		prop["items"] = items
+		return "array"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	}
+
+	return ""
+}
+
+// the regular expression matching the keys a Schema key with wildcards stands for
+func schemaKeyRegexp(key string) string {
+	segs := splitKey(key)
+	for idx, seg := range segs {
+		switch seg {
+		case SCHEMA_SLICE_INDEX:
+			segs[idx] = "[0-9]+"
+		case SCHEMA_MAP_KEY:
+			segs[idx] = "[^.]+"
+		case SCHEMA_RECURSIVE:
+			segs[idx] = ".+"
+		default:
+			segs[idx] = regexp.QuoteMeta(seg)
+		}
+	}
+
+	return "^" + strings.Join(segs, `\.`) + "$"
+}
+
+// true if the key holds a Schema wildcard segment
+func isSchemaPattern(key string) bool {
+	for _, seg := range splitKey(key) {
+		if seg == SCHEMA_SLICE_INDEX || seg == SCHEMA_MAP_KEY || seg == SCHEMA_RECURSIVE {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,309 @@
+package struct2map
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type jsonSchemaTestServer struct {
+	Host string `struct2map:"host" desc:"host name" min:"1" max:"253"`
+	Port uint16 `struct2map:"port,required" min:"1024"`
+}
+
+type jsonSchemaTestConfig struct {
+	Name     string                 `struct2map:"name,required" desc:"service name"`
+	Level    string                 `struct2map:"level" enum:"debug|info|warn"`
+	Workers  int                    `struct2map:"workers" enum:"1|2|4" max:"4"`
+	Ratio    *float64               `struct2map:"ratio" min:"0" max:"1"`
+	Debug    bool                   `struct2map:"debug"`
+	Small    int8                   `struct2map:"small"`
+	Started  time.Time              `struct2map:"started"`
+	Server   jsonSchemaTestServer   `struct2map:"server"`
+	Backups  []jsonSchemaTestServer `struct2map:"backups"`
+	Labels   map[string]string      `struct2map:"labels"`
+	Password string                 `struct2map:"password,redact" min:"8"`
+	Tree     schemaTestNode         `struct2map:"tree"`
+	Extra    any                    `struct2map:"extra,omitempty"`
+}
+
+func Test_JSONSchema(t *testing.T) {
+	hostProp := map[string]any{"type": "string", "description": "host name", "minLength": 1.0, "maxLength": 253.0}
+	portProp := map[string]any{"type": "integer", "minimum": 1024.0, "maximum": 65535.0}
+
+	expected := map[string]any{
+		"$schema":              JSON_SCHEMA_DIALECT,
+		"type":                 "object",
+		"additionalProperties": false,
+		"required":             []any{"name", "server.port"},
+		"properties": map[string]any{
+			"name":        map[string]any{"type": "string", "description": "service name"},
+			"level":       map[string]any{"type": "string", "enum": []any{"debug", "info", "warn"}},
+			"workers":     map[string]any{"type": "integer", "enum": []any{1.0, 2.0, 4.0}, "maximum": 4.0},
+			"ratio":       map[string]any{"type": []any{"number", "null"}, "minimum": 0.0, "maximum": 1.0},
+			"debug":       map[string]any{"type": "boolean"},
+			"small":       map[string]any{"type": "integer", "minimum": -128.0, "maximum": 127.0},
+			"started":     map[string]any{"type": "string", "format": "date-time"},
+			"server.host": hostProp,
+			"server.port": portProp,
+			"password":    map[string]any{"type": "string"},
+			"tree.Name":   map[string]any{"type": "string"},
+			"extra":       map[string]any{},
+		},
+		"patternProperties": map[string]any{
+			`^backups\.[0-9]+\.host$`:      hostProp,
+			`^backups\.[0-9]+\.port$`:      portProp,
+			`^labels\.[^.]+$`:              map[string]any{"type": "string"},
+			`^extra\..+$`:                  map[string]any{},
+			`^tree\.Children\.[0-9]+\..+$`: map[string]any{},
+			`^tree\.Parent\..+$`:           map[string]any{},
+		},
+	}
+
+	doc, err := JSONSchema(jsonSchemaTestConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var res map[string]any
+	if err := json.Unmarshal(doc, &res); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(res, expected) {
+		t.Fatalf("result mismatch\nexpected: %v\ngot:      %v", expected, res)
+	}
+}
+
+func Test_JSONSchemaOptions(t *testing.T) {
+	doc, err := JSONSchema(reflect.TypeOf(jsonSchemaTestServer{}), STRUCT_CONVERT_MAPKEY_TOUPPER)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var res struct {
+		Properties map[string]any `json:"properties"`
+		Required   []string       `json:"required"`
+	}
+	if err := json.Unmarshal(doc, &res); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(res.Properties) != 2 || res.Properties["HOST"] == nil || res.Properties["PORT"] == nil || !reflect.DeepEqual(res.Required, []string{"PORT"}) {
+		t.Fatalf("unexpected schema: %s", doc)
+	}
+
+	doc, err = JSONSchema(jsonSchemaTestConfig{}, REDACT_DROP)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := json.Unmarshal(doc, &res); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if _, ok := res.Properties["password"]; ok {
+		t.Fatalf("dropped redacted key still in the schema: %s", doc)
+	}
+}
+
+func Test_JSONSchemaErrors(t *testing.T) {
+	testSet := []struct {
+		Name     string
+		Input    any
+		ExpErr   error
+		SkipTest bool
+	}{
+		{
+			Name:  "not a structure",
+			Input: []int{},
+		},
+		{
+			Name: "bad enum value",
+			Input: struct {
+				Workers int `enum:"1|two"`
+			}{},
+			ExpErr: ErrTypeMismatch,
+		},
+		{
+			Name: "bad min",
+			Input: struct {
+				Ratio float64 `min:"low"`
+			}{},
+			ExpErr: ErrTypeMismatch,
+		},
+		{
+			Name: "bad max length",
+			Input: struct {
+				Name string `max:"-1"`
+			}{},
+			ExpErr: ErrTypeMismatch,
+		},
+	}
+
+	for _, test := range testSet {
+		t.Run(test.Name, func(t *testing.T) {
+			if test.SkipTest {
+				t.Skipf("skipping test")
+			}
+
+			_, err := JSONSchema(test.Input)
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if test.ExpErr != nil {
+				var keyErr *KeyError
+				if !errors.Is(err, test.ExpErr) || !errors.As(err, &keyErr) {
+					t.Fatalf("expected a *KeyError wrapping %v, got %v", test.ExpErr, err)
+				}
+			}
+		})
+	}
+}
+
+// every key ConvertStruct produces must be described by the schema
+func Test_JSONSchemaCoversConvert(t *testing.T) {
+	ratio := 0.5
+	cfg := jsonSchemaTestConfig{
+		Name:    "svc",
+		Ratio:   &ratio,
+		Backups: []jsonSchemaTestServer{{Host: "a", Port: 2000}},
+		Labels:  map[string]string{"team": "core"},
+		Tree:    schemaTestNode{Name: "root", Children: []schemaTestNode{{Name: "child"}}},
+	}
+
+	doc, err := JSONSchema(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var res struct {
+		Properties        map[string]any `json:"properties"`
+		PatternProperties map[string]any `json:"patternProperties"`
+	}
+	if err := json.Unmarshal(doc, &res); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	for key := range ConvertStruct(cfg) {
+		if _, ok := res.Properties[key]; ok {
+			continue
+		}
+
+		matched := false
+		for pattern := range res.PatternProperties {
+			if regexp.MustCompile(pattern).MatchString(key) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			t.Errorf("key %s is not described by the schema", key)
+		}
+	}
+}
+
+type jsonSchemaTestNode struct {
+	Name string              `struct2map:"name"`
+	Next *jsonSchemaTestNode `struct2map:"next"`
+}
+
+type jsonSchemaTestOutput struct {
+	Backup *jsonSchemaTestServer `struct2map:"backup"`
+	Extra  any                   `struct2map:"extra"`
+	Head   jsonSchemaTestNode    `struct2map:"head"`
+	Port   int                   `struct2map:"port"`
+}
+
+// the problems the schema finds with a flat map; only the keywords JSONSchema outputs for the document itself (and
+// null for the type of a property) are checked
+func jsonSchemaProblems(t *testing.T, doc []byte, m map[string]any) []string {
+	var schema struct {
+		Properties        map[string]map[string]any `json:"properties"`
+		PatternProperties map[string]map[string]any `json:"patternProperties"`
+		Required          []string                  `json:"required"`
+		AllOf             []struct {
+			AnyOf []struct {
+				Required []string `json:"required"`
+			} `json:"anyOf"`
+		} `json:"allOf"`
+	}
+	if err := json.Unmarshal(doc, &schema); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	var ret []string
+	for key, val := range m {
+		prop, ok := schema.Properties[key]
+		for pattern, patternProp := range schema.PatternProperties {
+			if !ok && regexp.MustCompile(pattern).MatchString(key) {
+				prop, ok = patternProp, true
+			}
+		}
+		if !ok {
+			ret = append(ret, key+" is not allowed")
+			continue
+		}
+
+		nullable, _ := prop["type"].([]any)
+		if _, typed := prop["type"]; val == nil && typed && (len(nullable) != 2 || nullable[1] != "null") {
+			ret = append(ret, key+" cannot be null")
+		}
+	}
+
+	for _, key := range schema.Required {
+		if _, ok := m[key]; !ok {
+			ret = append(ret, key+" is missing")
+		}
+	}
+	for _, entry := range schema.AllOf {
+		found := false
+		for _, alternative := range entry.AnyOf {
+			if _, ok := m[alternative.Required[0]]; ok {
+				found = true
+			}
+		}
+		if !found {
+			ret = append(ret, fmt.Sprintf("none of %v are present", entry.AnyOf))
+		}
+	}
+
+	return ret
+}
+
+// the output of ConvertStruct (and Convert) must be valid against the schema, as it is for Validate
+func Test_JSONSchemaValidatesConvert(t *testing.T) {
+	doc, err := JSONSchema(jsonSchemaTestOutput{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	values := []jsonSchemaTestOutput{
+		{},
+		{
+			Backup: &jsonSchemaTestServer{Host: "b", Port: 2000},
+			Extra:  jsonSchemaTestServer{Host: "e"},
+			Head:   jsonSchemaTestNode{Name: "a", Next: &jsonSchemaTestNode{Name: "b"}},
+			Port:   80,
+		},
+	}
+	for idx, val := range values {
+		if res := Validate[jsonSchemaTestOutput](ConvertStruct(val)); res != nil {
+			t.Errorf("value %d: Validate rejected the ConvertStruct output: %v", idx, res)
+		}
+
+		for _, genMap := range []map[string]any{ConvertStruct(val), Convert(val)} {
+			if problems := jsonSchemaProblems(t, doc, genMap); problems != nil {
+				t.Errorf("value %d: schema rejected %v: %v", idx, genMap, problems)
+			}
+		}
+	}
+
+	// the required port below the pointer must be there when the pointer is not nil
+	if problems := jsonSchemaProblems(t, doc, map[string]any{"backup.host": "b", "extra": nil, "head.name": "", "head.next": nil, "port": 0}); len(problems) != 1 {
+		t.Errorf("expected the missing backup.port to be found, got %v", problems)
+	}
+	if res := Validate[jsonSchemaTestOutput](map[string]any{"backup.host": "b"}); len(res) != 1 || res[0].Key != "backup.port" {
+		t.Errorf("expected Validate to find the missing backup.port, got %v", res)
+	}
+}
@@ -0,0 +1,126 @@
+package struct2map
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// sorts flattened keys segment by segment so the output is stable and reads naturally;
+// numeric segments (slice indexes) are compared by value so Tags.2 sorts before Tags.10
+func sortKeys(keys []string) {
+	sort.SliceStable(keys, func(i, j int) bool {
+		return compareKeys(keys[i], keys[j]) < 0
+	})
+}
+
+func compareKeys(a, b string) int {
+	aSegs := strings.Split(a, ".")
+	bSegs := strings.Split(b, ".")
+
+	for idx := 0; idx < len(aSegs) && idx < len(bSegs); idx++ {
+		if aSegs[idx] == bSegs[idx] {
+			continue
+		}
+
+		aNum, aErr := strconv.ParseUint(aSegs[idx], 10, 64)
+		bNum, bErr := strconv.ParseUint(bSegs[idx], 10, 64)
+		if aErr == nil && bErr == nil && aNum != bNum {
+			if aNum < bNum {
+				return -1
+			}
+			return 1
+		}
+
+		return strings.Compare(aSegs[idx], bSegs[idx])
+	}
+
+	return len(aSegs) - len(bSegs)
+}
+
+// true if key is the path itself or lives somewhere underneath it
+func keyHasPrefix(key, path string) bool {
+	return key == path || strings.HasPrefix(key, path+".")
+}
+
+// splits a flattened key into its segments
+func splitKey(key string) []string {
+	return strings.Split(key, ".")
+}
+
+// the reverse of splitKey
+func joinKey(segs []string) string {
+	return strings.Join(segs, ".")
+}
+
+// true if key matches the glob pattern, which is matched segment by segment; * within a segment matches any run of
+// characters (so a lone * matches exactly one segment), a # segment matches exactly one slice index and a ** segment
+// matches any number of segments, including none (ex: *.Password matches Admin.Password, Tags.# matches Tags.3 but not
+// Tags.x, Auth.** matches Auth and everything under it)
+func matchKeyGlob(pattern, key string) bool {
+	return matchGlobSegs(splitKey(pattern), splitKey(key))
+}
+
+func matchGlobSegs(pattern, segs []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for skip := 0; skip <= len(segs); skip++ {
+				if matchGlobSegs(pattern[1:], segs[skip:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(segs) == 0 || !matchGlobSeg(pattern[0], segs[0]) {
+			return false
+		}
+		pattern, segs = pattern[1:], segs[1:]
+	}
+
+	return len(segs) == 0
+}
+
+// true if segs could be extended with further segments into a key matching the (split) glob pattern
+func matchGlobPrefix(pattern, segs []string) bool {
+	for ; len(segs) > 0; pattern, segs = pattern[1:], segs[1:] {
+		if len(pattern) == 0 {
+			return false
+		}
+		if pattern[0] == "**" {
+			return true
+		}
+		if !matchGlobSeg(pattern[0], segs[0]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchGlobSeg(pattern, seg string) bool {
+	if pattern == "#" {
+		_, ok := parseSliceIndex(seg)
+		return ok
+	}
+
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == seg
+	}
+
+	if !strings.HasPrefix(seg, parts[0]) {
+		return false
+	}
+	seg = seg[len(parts[0]):]
+
+	for _, part := range parts[1 : len(parts)-1] {
+		idx := strings.Index(seg, part)
+		if idx < 0 {
+			return false
+		}
+		seg = seg[idx+len(part):]
+	}
+
+	return strings.HasSuffix(seg, parts[len(parts)-1])
+}
@@ -0,0 +1,247 @@
+package struct2map
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var ErrMissingRequired = errors.New("required value is missing")
+
+// a source of named string values (ex: the environment) that structures can be loaded from
+type nameSource struct {
+	lookup   func(name string) ([]string, bool)
+	names    []string // every available name, used to discover map entries; nil when they cannot be listed
+	foldCase bool     // names compare case insensitively
+}
+
+// a value found in a nameSource along with the key segments it is assigned to
+type namedValue struct {
+	name string
+	segs []string
+	val  any // string or []string
+}
+
+// Walks a type, deriving a name for every value it can hold (nameOf) and looking each of those names up in a
+// nameSource; this is how the reverse conversions from sources that cannot be listed as flattened keys (ex: the
+// environment, where names have lost their original case) find their values
+type nameLoader struct {
+	cfg      *convertConfig
+	src      nameSource
+	nameOf   func(segs []string) string
+	sep      string // separator nameOf places between segments, used to discover map entries
+	sliceSep string // if set, a lone value found for a slice of plain values is split on it
+
+	found      []namedValue
+	errs       []error
+	inProgress map[reflect.Type]bool // guards against recursive types
+}
+
+// cur is the value already held at segs (invalid when there is none), used to recover the case of map keys
+//
+// Returns: true if anything at all was found for the type at segs
+func (l *nameLoader) walk(t reflect.Type, cur reflect.Value, segs []string) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		if cur.IsValid() && cur.Kind() == reflect.Pointer {
+			cur = cur.Elem()
+		}
+	}
+	if cur.IsValid() && cur.Type() != t {
+		cur = reflect.Value{}
+	}
+
+	if isLeafType(t) {
+		name := l.nameOf(segs)
+		vals, ok := l.src.lookup(name)
+		if !ok || len(vals) == 0 {
+			return false
+		}
+
+		l.found = append(l.found, namedValue{name: name, segs: segs, val: vals[0]})
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if l.inProgress[t] {
+			return false
+		}
+		l.inProgress[t] = true
+		defer delete(l.inProgress, t)
+
+		found := false
+		parentSegs := segs
+		for pos := 0; pos < t.NumField(); pos++ {
+			tag := parseFieldTag(l.cfg, t.Field(pos))
+			if tag.skip || tag.unexported {
+				continue
+			}
+
+			// as in structToMap, ignoring parents drops the prefix for this field and every field after it
+			if tag.ignoreParents {
+				parentSegs = nil
+			}
+			childSegs := appendSeg(parentSegs, fieldKeyName(l.cfg, tag))
+
+			var field reflect.Value
+			if cur.IsValid() {
+				field = cur.Field(pos)
+			}
+
+			// a nil pointer need not hold its required fields unless it is required itself
+			mark := len(l.errs)
+			if l.walk(t.Field(pos).Type, field, childSegs) {
+				found = true
+				continue
+			}
+			if t.Field(pos).Type.Kind() == reflect.Pointer && !tag.required {
+				l.errs = l.errs[:mark]
+			}
+			if tag.required && !tag.hasDefault {
+				l.errs = append(l.errs, &KeyError{Key: l.nameOf(childSegs), Err: ErrMissingRequired})
+			}
+		}
+		return found
+	case reflect.Slice, reflect.Array:
+		if isLeafType(t.Elem()) {
+			name := l.nameOf(segs)
+			if vals, ok := l.src.lookup(name); ok && len(vals) > 0 {
+				if len(vals) == 1 && l.sliceSep != "" {
+					vals = strings.Split(vals[0], l.sliceSep)
+				}
+
+				l.found = append(l.found, namedValue{name: name, segs: segs, val: vals})
+				return true
+			}
+		}
+
+		limit := l.cfg.sliceIndexLimit()
+		if t.Kind() == reflect.Array {
+			limit = t.Len() - 1
+		}
+
+		found := false
+		for idx := 0; idx <= limit; idx++ {
+			var item reflect.Value
+			if cur.IsValid() && idx < cur.Len() {
+				item = cur.Index(idx)
+			}
+
+			// probing past the last item finds nothing, and neither do the required fields of an item that is not there
+			mark := len(l.errs)
+			if !l.walk(t.Elem(), item, appendSeg(segs, strconv.Itoa(idx))) {
+				l.errs = l.errs[:mark]
+				break
+			}
+			found = true
+		}
+		return found
+	case reflect.Map:
+		// map entries can only be discovered by listing every name; only plain values are supported as the
+		// rest of the name cannot be reliably split into a map key and the fields of a value
+		if l.src.names == nil || !isLeafType(t.Elem()) {
+			return false
+		}
+
+		prefix := l.nameOf(segs) + l.sep
+		found := false
+		for _, name := range l.src.names {
+			if len(name) <= len(prefix) {
+				continue
+			}
+			if (l.src.foldCase && !strings.EqualFold(name[:len(prefix)], prefix)) || (!l.src.foldCase && name[:len(prefix)] != prefix) {
+				continue
+			}
+
+			vals, ok := l.src.lookup(name)
+			if !ok || len(vals) == 0 {
+				continue
+			}
+
+			l.found = append(l.found, namedValue{name: name, segs: appendSeg(segs, l.mapKey(cur, segs, name, prefix)), val: vals[0]})
+			found = true
+		}
+		return found
+	}
+
+	return false
+}
+
+// the map key name was found under; names rarely keep the case of the key (ex: the environment), so a key the map
+// already holds is reused when its own name matches (case insensitively), otherwise the rest of the name is the key
+func (l *nameLoader) mapKey(cur reflect.Value, segs []string, name string, prefix string) string {
+	ret := ""
+	if cur.IsValid() && cur.Kind() == reflect.Map {
+		iter := cur.MapRange()
+		for iter.Next() {
+			// several keys can share a name (ex: "host" and "HOST"), pick the same one every time
+			seg := mapSubKey(l.cfg, iter.Key())
+			if strings.EqualFold(l.nameOf(appendSeg(segs, seg)), name) && (ret == "" || seg < ret) {
+				ret = seg
+			}
+		}
+	}
+	if ret != "" {
+		return ret
+	}
+
+	return name[len(prefix):]
+}
+
+// assigns everything found to the target; like Patch, either everything is assigned or the target is left untouched
+func (l *nameLoader) apply(target reflect.Value) error {
+	if err := defaultsErr(target.Type()); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(l.found))
+	for _, found := range l.found {
+		keys = append(keys, joinKey(found.segs))
+	}
+
+	working, _ := applyDefaults(l.cfg, target, nil, inputKeys(keys))
+	for _, found := range l.found {
+		newVal, err := assignPath(l.cfg, working, found.segs, found.val, true)
+		if err != nil {
+			l.errs = append(l.errs, &KeyError{Key: found.name, Err: err})
+			continue
+		}
+		working = newVal
+	}
+
+	if len(l.errs) > 0 {
+		return errors.Join(l.errs...)
+	}
+
+	target.Set(working)
+	return nil
+}
+
+// true for the types that are loaded from a single value rather than walked into
+func isLeafType(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return isScalarType(t) || t.Kind() == reflect.Interface || reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// copies segs before appending so sibling walks never share a backing array
+func appendSeg(segs []string, seg string) []string {
+	ret := make([]string, len(segs), len(segs)+1)
+	copy(ret, segs)
+	return append(ret, seg)
+}
+
+// the value pointed to by dest, which every reverse conversion requires
+func targetValue(funcName string, dest any) (reflect.Value, error) {
+	target := reflect.ValueOf(dest)
+	if target.Kind() != reflect.Pointer || target.IsNil() {
+		return reflect.Value{}, fmt.Errorf("struct2map: %s requires a non-nil pointer, got %T", funcName, dest)
+	}
+
+	return target.Elem(), nil
+}
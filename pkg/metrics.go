@@ -0,0 +1,211 @@
+package struct2map
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
+
+const METRICS_CONTENT_TYPE = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+var ErrInvalidMetricName = errors.New("invalid metric or label name")
+
+// Prefixes every metric name WriteMetrics writes with namespace and an underscore (ex: "app" turns Server.Port into
+// app_server_port)
+func MetricsNamespace(namespace string) Option {
+	return optionFunc(func(cfg *convertConfig) {
+		cfg.metricsNamespace = namespace
+	})
+}
+
+// Takes a structure (or any value Convert accepts) and writes it to w in the OpenMetrics text exposition format;
+// allows passing of various options (see StructConvertOpts constants and MetricsNamespace)
+//
+// Every numeric and bool value (as 1 or 0) becomes a gauge named after its flattened key, with each segment converted
+// to snake case, the segments joined by underscores and any character not allowed in a metric name replaced by an
+// underscore (ex: Server.maxConns becomes server_max_conns). Fields tagged with the label option become a label pair
+// on every gauge instead, named the same way (ex: `struct2map:"region,label"`). All other values (ex: untagged
+// strings, nil values) are skipped.
+//
+// Returns: nil on success or an error if obj cannot be converted, the namespace or a generated name is not valid (or
+// two keys generate the same name) or writing to w fails
+func WriteMetrics(w io.Writer, obj any, opts ...Option) error {
+	cfg := newConvertConfig(opts...)
+	cfg.metricsLabels = make(map[string]bool)
+
+	flat := convertToMap(cfg, obj)
+	if flat == nil {
+		return fmt.Errorf("struct2map: cannot convert %T", obj)
+	}
+
+	if cfg.metricsNamespace != "" && !validMetricName(cfg.metricsNamespace) {
+		return fmt.Errorf("struct2map: namespace %q: %w", cfg.metricsNamespace, ErrInvalidMetricName)
+	}
+
+	keys := make([]string, 0, len(flat))
+	for k := range flat {
+		keys = append(keys, k)
+	}
+	sortKeys(keys) // so a name generated twice is always reported against the same key
+
+	var labels []string
+	gauges := make(map[string]string)
+	var errs []error
+	for _, k := range keys {
+		v := flat[k]
+		if cfg.metricsLabels[k] {
+			name := metricName("", k)
+			if !validLabelName(name) {
+				errs = append(errs, &KeyError{Key: k, Err: ErrInvalidMetricName})
+				continue
+			}
+			labels = append(labels, fmt.Sprintf(`%s="%s"`, name, escapeLabelValue(formatValue(cfg, v))))
+			continue
+		}
+
+		value, ok := metricValue(v)
+		if !ok {
+			continue
+		}
+		name := metricName(cfg.metricsNamespace, k)
+		if !validMetricName(name) {
+			errs = append(errs, &KeyError{Key: k, Err: ErrInvalidMetricName})
+			continue
+		}
+		if _, exists := gauges[name]; exists {
+			errs = append(errs, &KeyError{Key: k, Err: fmt.Errorf("%w: %s is generated by more than one key", ErrInvalidMetricName, name)})
+			continue
+		}
+		gauges[name] = value
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	sort.Strings(labels)
+	labelSet := ""
+	if len(labels) > 0 {
+		labelSet = fmt.Sprintf("{%s}", strings.Join(labels, ","))
+	}
+
+	names := make([]string, 0, len(gauges))
+	for name := range gauges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bufW := bufio.NewWriter(w)
+	for _, name := range names {
+		fmt.Fprintf(bufW, "# TYPE %s gauge\n%s%s %s\n", name, name, labelSet, gauges[name])
+	}
+	bufW.WriteString("# EOF\n")
+
+	return bufW.Flush()
+}
+
+// Takes a function returning the structure to export and serves it in the OpenMetrics text exposition format (see
+// WriteMetrics) on every request; allows passing of various options (see StructConvertOpts constants and
+// MetricsNamespace)
+//
+// Returns: the http.Handler; requests that fail to export are answered with a 500 status
+func MetricsHandler(source func() any, opts ...Option) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// write to a buffer first so a failed export never sends a partial exposition
+		var buf bytes.Buffer
+		if err := WriteMetrics(&buf, source(), opts...); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", METRICS_CONTENT_TYPE)
+		w.Write(buf.Bytes())
+	})
+}
+
+// builds the metric (or label) name for a flattened key, with the namespace prefixed if set
+func metricName(namespace string, key string) string {
+	segs := splitKey(key)
+	parts := make([]string, 0, len(segs)+1)
+	if namespace != "" {
+		parts = append(parts, namespace)
+	}
+	for _, seg := range segs {
+		parts = append(parts, strcase.ToSnake(seg))
+	}
+
+	name := []byte(strings.Join(parts, "_"))
+	for idx, char := range name {
+		isAlnum := (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9')
+		if !isAlnum && char != '_' && char != ':' {
+			name[idx] = '_'
+		}
+	}
+
+	return string(name)
+}
+
+// the gauge value for a numeric or bool value; ok is false for anything else
+func metricValue(val any) (ret string, ok bool) {
+	valOf := reflect.ValueOf(val)
+	switch valOf.Kind() {
+	case reflect.Bool:
+		if valOf.Bool() {
+			return "1", true
+		}
+		return "0", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(valOf.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(valOf.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		switch floatVal := valOf.Float(); {
+		case math.IsNaN(floatVal):
+			return "NaN", true
+		case math.IsInf(floatVal, 1):
+			return "+Inf", true
+		case math.IsInf(floatVal, -1):
+			return "-Inf", true
+		default:
+			return strconv.FormatFloat(floatVal, 'g', -1, valOf.Type().Bits()), true
+		}
+	}
+
+	return "", false
+}
+
+// true if name matches [a-zA-Z_:][a-zA-Z0-9_:]*
+func validMetricName(name string) bool {
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return false
+	}
+
+	for idx := 0; idx < len(name); idx++ {
+		char := name[idx]
+		isAlnum := (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9')
+		if !isAlnum && char != '_' && char != ':' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// true if name matches [a-zA-Z_][a-zA-Z0-9_]* and does not use the reserved __ prefix
+func validLabelName(name string) bool {
+	return validMetricName(name) && !strings.Contains(name, ":") && !strings.HasPrefix(name, "__")
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
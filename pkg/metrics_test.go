@@ -0,0 +1,136 @@
+package struct2map
+
+import (
+	"errors"
+	"io"
+	"math"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type metricsTestStatus struct {
+	Region  string            `struct2map:"region,label"`
+	Node    string            `struct2map:"node,label"`
+	Healthy bool              `struct2map:"healthy"`
+	Version string            `struct2map:"version"`
+	Server  envTestServer     `struct2map:"server"`
+	Load    float64           `struct2map:"load"`
+	Queues  map[string]uint   `struct2map:"queues"`
+	Backup  *envTestServer    `struct2map:"backup"`
+	Extra   map[string]string `struct2map:"extra"`
+}
+
+type metricsTestCollision struct {
+	MaxConns int `struct2map:"maxConns"`
+	Max      struct {
+		Conns int `struct2map:"conns"`
+	} `struct2map:"max"`
+}
+
+func Test_WriteMetrics(t *testing.T) {
+	testStatus := metricsTestStatus{
+		Region:  "eu-west",
+		Node:    `n"1\`,
+		Healthy: true,
+		Version: "1.2.3",
+		Server:  envTestServer{Host: "localhost", Port: 8080},
+		Load:    0.75,
+		Queues:  map[string]uint{"high-prio": 3},
+		Extra:   map[string]string{"note": "skipped"},
+	}
+
+	testSet := []struct {
+		Name            string
+		TestStruct      any
+		MetricsOpts     []Option
+		ExpectedMetrics string
+		ExpErrKeys      []string
+		SkipTest        bool
+	}{
+		{
+			Name:        "gauges with labels and namespace",
+			TestStruct:  testStatus,
+			MetricsOpts: []Option{MetricsNamespace("app")},
+			ExpectedMetrics: "# TYPE app_healthy gauge\n" +
+				"app_healthy{node=\"n\\\"1\\\\\",region=\"eu-west\"} 1\n" +
+				"# TYPE app_load gauge\n" +
+				"app_load{node=\"n\\\"1\\\\\",region=\"eu-west\"} 0.75\n" +
+				"# TYPE app_queues_high_prio gauge\n" +
+				"app_queues_high_prio{node=\"n\\\"1\\\\\",region=\"eu-west\"} 3\n" +
+				"# TYPE app_server_port gauge\n" +
+				"app_server_port{node=\"n\\\"1\\\\\",region=\"eu-west\"} 8080\n" +
+				"# EOF\n",
+		},
+		{
+			Name:       "no labels and special floats",
+			TestStruct: struct{ Ratio, Max float64 }{Ratio: math.NaN(), Max: math.Inf(1)},
+			ExpectedMetrics: "# TYPE max gauge\nmax +Inf\n" +
+				"# TYPE ratio gauge\nratio NaN\n" +
+				"# EOF\n",
+		},
+		{
+			Name:       "names generated twice",
+			TestStruct: metricsTestCollision{},
+			ExpErrKeys: []string{"maxConns"},
+		},
+		{
+			Name:       "names starting with a digit",
+			TestStruct: []int{1},
+			ExpErrKeys: []string{"0"},
+		},
+	}
+
+	for _, curTest := range testSet {
+		t.Run(curTest.Name, func(t *testing.T) {
+			if curTest.SkipTest {
+				t.Skipf("skipped '%s' due to SkipTest being set", curTest.Name)
+			}
+
+			var sb strings.Builder
+			err := WriteMetrics(&sb, curTest.TestStruct, curTest.MetricsOpts...)
+			if curTest.ExpErrKeys != nil {
+				var keyErr *KeyError
+				if !errors.As(err, &keyErr) || !errors.Is(err, ErrInvalidMetricName) {
+					t.Fatalf("expected an ErrInvalidMetricName *KeyError, got: %v", err)
+				}
+				if errKeys := keyErrorKeys(t, err); !reflect.DeepEqual(errKeys, curTest.ExpErrKeys) {
+					t.Errorf("failed keys not as expected\nHave: %v\nWant: %v", errKeys, curTest.ExpErrKeys)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sb.String() != curTest.ExpectedMetrics {
+				t.Errorf("generated metrics not the same as the expected metrics\nHave:\n%s\nWant:\n%s", sb.String(), curTest.ExpectedMetrics)
+			}
+		})
+	}
+
+	if err := WriteMetrics(io.Discard, testStatus, MetricsNamespace("1app")); !errors.Is(err, ErrInvalidMetricName) {
+		t.Errorf("expected an invalid namespace error, got: %v", err)
+	}
+}
+
+func Test_MetricsHandler(t *testing.T) {
+	status := metricsTestStatus{Region: "eu", Healthy: true}
+	handler := MetricsHandler(func() any { return status })
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Code != 200 || rec.Header().Get("Content-Type") != METRICS_CONTENT_TYPE {
+		t.Fatalf("unexpected response: %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rec.Body.String(), "healthy{node=\"\",region=\"eu\"} 1\n") || !strings.HasSuffix(rec.Body.String(), "# EOF\n") {
+		t.Errorf("unexpected body:\n%s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	MetricsHandler(func() any { return 1 }).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Code != 500 {
+		t.Errorf("expected a 500 status for a failed export, got %d", rec.Code)
+	}
+}
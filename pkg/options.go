@@ -0,0 +1,95 @@
+package struct2map
+
+import "github.com/newodahs/struct2map/internal"
+
+// An Option modifies how a conversion (or any of the functions built on top of one) behaves.
+//
+// The StructConvertOpts constants are Options themselves, so they can be mixed freely with the
+// parameterized options returned by the functions in this package; options that do not apply to
+// a particular function are simply ignored by it.
+type Option interface {
+	apply(cfg *convertConfig)
+}
+
+// the collected state of all the options passed to a conversion
+type convertConfig struct {
+	nameModFunc       func(string) string
+	maxSliceIndex     int
+	includeUnexported bool
+	unexportedMarker  string
+	keepSlices        bool // slices of scalar values are stored whole instead of one key per index
+	maxDepth          int
+	nestedContainers  bool                  // interfaces and containers within containers are walked rather than stored whole; set by Convert
+	wholePath         map[visitedValue]bool // the pointers followed to reach the value being walked by wholeToMap; only set during conversion
+
+	// string format options; used by every text based encoder
+	stringFormat internal.StringFormat
+
+	// field group options
+	activeGroups    []string
+	inheritedGroups []string // the groups of the field currently being walked; only set during conversion
+
+	// key filtering options
+	includeRules []string
+	excludeRules []string
+
+	// redaction options
+	redactRules []string
+	redactMode  RedactMode
+	redactMask  string
+
+	// environment variable options
+	envPrefix         string
+	envSeparator      string
+	envSliceSeparator string
+	envSource         []string
+	envLookup         func(string) (string, bool)
+
+	// .properties/INI options
+	propertiesINI bool
+
+	// url.Values options
+	urlSliceEncoding URLSliceEncoding
+
+	// CSV options
+	csvColumns []string
+
+	// slog options
+	logFlat bool
+
+	// metrics options
+	metricsNamespace string
+	metricsLabels    map[string]bool // filled in during conversion with the keys of the fields tagged as labels
+
+	// Diff options
+	diffIgnorePaths    []string
+	diffNilEqualsEmpty bool
+	diffFloatTolerance float64
+}
+
+// adapts a plain function into an Option
+type optionFunc func(cfg *convertConfig)
+
+func (f optionFunc) apply(cfg *convertConfig) {
+	f(cfg)
+}
+
+func newConvertConfig(opts ...Option) *convertConfig {
+	cfg := &convertConfig{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt.apply(cfg)
+		}
+	}
+
+	return cfg
+}
+
+// the largest slice index an assignment may grow a slice to
+func (cfg *convertConfig) sliceIndexLimit() int {
+	if cfg.maxSliceIndex > 0 {
+		return cfg.maxSliceIndex
+	}
+
+	return defaultMaxSliceIndex
+}
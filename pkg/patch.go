@@ -0,0 +1,66 @@
+package struct2map
+
+import (
+	"errors"
+	"reflect"
+)
+
+type PatchOp uint
+
+const (
+	PATCH_NOOP   PatchOp = iota // does nothing; the key is skipped
+	PATCH_DELETE                // deletes a map entry, truncates a slice at the keyed index or resets any other field to its zero value
+)
+
+// Applies a set of changes keyed by flattened key names (ex: "Server.Port", "Tags.2") to the structure pointed to
+// by objPtr; allows passing of various options (see StructConvertOpts constants) which must match the options used
+// to produce the keys.
+//
+// Keys follow the same rules as ConvertStruct with the exception of maps holding structures, where the map key is
+// always expected between the field name and the structure's fields (ex: "Servers.primary.Port"). Slices are grown
+// as needed to reach a keyed index. Values are converted to the field type where that can be done without loss;
+// strings are parsed into the field type.
+//
+// The changes are applied atomically; either every change succeeds or the target is left untouched.
+//
+// Returns: nil on success or an error joining a *KeyError for every key that failed
+func Patch(objPtr any, changes map[string]any, opts ...Option) error {
+	cfg := newConvertConfig(opts...)
+
+	target, err := targetValue("Patch", objPtr)
+	if err != nil {
+		return err
+	}
+
+	return patchValue(cfg, target, target, changes)
+}
+
+// applies changes to working (target itself or a copy of it) and sets target to the result once every change succeeded
+func patchValue(cfg *convertConfig, target reflect.Value, working reflect.Value, changes map[string]any) error {
+	keys := make([]string, 0, len(changes))
+	for k := range changes {
+		keys = append(keys, k)
+	}
+	sortKeys(keys) // apply in a stable order so slices grow index by index
+
+	var errs []error
+	for _, k := range keys {
+		if changes[k] == PATCH_NOOP {
+			continue
+		}
+
+		newVal, err := assignPath(cfg, working, splitKey(k), changes[k], true)
+		if err != nil {
+			errs = append(errs, &KeyError{Key: k, Err: err})
+			continue
+		}
+		working = newVal
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	target.Set(working)
+	return nil
+}
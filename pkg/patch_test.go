@@ -0,0 +1,212 @@
+package struct2map
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type patchTestServer struct {
+	Host    string
+	Port    int
+	Timeout time.Duration
+}
+
+type patchTestConfig struct {
+	Name     string                      `struct2map:"name"`
+	Server   patchTestServer             `struct2map:"server"`
+	Backup   *patchTestServer            `struct2map:"backup"`
+	Tags     []string                    `struct2map:"tags"`
+	Ports    []uint16                    `struct2map:"ports"`
+	Labels   map[string]string           `struct2map:"labels"`
+	Servers  map[string]*patchTestServer `struct2map:"servers"`
+	Fixed    [2]int                      `struct2map:"fixed"`
+	Extra    any                         `struct2map:"extra"`
+	Nested   patchTestNested             `struct2map:"nested"`
+	internal int
+}
+
+type patchTestNested struct {
+	Flattened bool   `struct2map:"flattened,ignoreparents"`
+	After     string `struct2map:"after"`
+}
+
+func newPatchTestConfig() patchTestConfig {
+	return patchTestConfig{
+		Name:     "svc",
+		Server:   patchTestServer{Host: "localhost", Port: 80},
+		Tags:     []string{"a", "b", "c"},
+		Labels:   map[string]string{"team": "core", "env": "dev"},
+		Servers:  map[string]*patchTestServer{"primary": {Host: "one", Port: 1}},
+		internal: 7,
+	}
+}
+
+func Test_Patch(t *testing.T) {
+	testSet := []struct {
+		Name       string
+		Changes    map[string]any
+		PatchOpts  []Option
+		ExpStruct  func() patchTestConfig
+		ExpErrKeys []string
+		SkipTest   bool
+	}{
+		{
+			Name:    "simple field changes with conversion",
+			Changes: map[string]any{"name": "svc2", "server.Port": int64(8080), "server.Timeout": "1m30s", "server.Host": PATCH_NOOP},
+			ExpStruct: func() patchTestConfig {
+				ret := newPatchTestConfig()
+				ret.Name = "svc2"
+				ret.Server.Port = 8080
+				ret.Server.Timeout = 90 * time.Second
+				return ret
+			},
+		},
+		{
+			Name:    "slices grow and truncate",
+			Changes: map[string]any{"tags.1": PATCH_DELETE, "ports.0": "443", "ports.1": 8443.0, "fixed.1": 5},
+			ExpStruct: func() patchTestConfig {
+				ret := newPatchTestConfig()
+				ret.Tags = []string{"a"}
+				ret.Ports = []uint16{443, 8443}
+				ret.Fixed[1] = 5
+				return ret
+			},
+		},
+		{
+			Name:    "arrays take whole values",
+			Changes: map[string]any{"fixed": []string{"3", "4"}},
+			ExpStruct: func() patchTestConfig {
+				ret := newPatchTestConfig()
+				ret.Fixed = [2]int{3, 4}
+				return ret
+			},
+		},
+		{
+			Name:    "maps get entries added and deleted",
+			Changes: map[string]any{"labels.env": PATCH_DELETE, "labels.region": "us", "servers.primary.Port": 2, "servers.backup.Host": "two"},
+			ExpStruct: func() patchTestConfig {
+				ret := newPatchTestConfig()
+				ret.Labels = map[string]string{"team": "core", "region": "us"}
+				ret.Servers = map[string]*patchTestServer{"primary": {Host: "one", Port: 2}, "backup": {Host: "two"}}
+				return ret
+			},
+		},
+		{
+			Name:    "nil pointers and interfaces are allocated",
+			Changes: map[string]any{"backup.Port": 9, "extra.key": 1},
+			ExpStruct: func() patchTestConfig {
+				ret := newPatchTestConfig()
+				ret.Backup = &patchTestServer{Port: 9}
+				ret.Extra = map[string]any{"key": 1}
+				return ret
+			},
+		},
+		{
+			Name:    "ignoreparents fields and the fields after them are addressed from the top",
+			Changes: map[string]any{"flattened": true, "after": "a"},
+			ExpStruct: func() patchTestConfig {
+				ret := newPatchTestConfig()
+				ret.Nested.Flattened = true
+				ret.Nested.After = "a"
+				return ret
+			},
+		},
+		{
+			Name:      "name modifiers apply to keys",
+			Changes:   map[string]any{"server.host": "remote"},
+			PatchOpts: []Option{STRUCT_CONVERT_MAPKEY_TOLOWER},
+			ExpStruct: func() patchTestConfig {
+				ret := newPatchTestConfig()
+				ret.Server.Host = "remote"
+				return ret
+			},
+		},
+		{
+			Name: "failures leave the target unchanged and name every key",
+			Changes: map[string]any{
+				"name":             "changed",
+				"labels.team":      "changed",
+				"server.Port":      "not a number",
+				"ports.0":          -1,
+				"tags.x":           "y",
+				"missing":          1,
+				"fixed":            []int{1, 2, 3},
+				"fixed.2":          1,
+				"internal":         1,
+				"nested.flattened": true,
+				"nested.after":     "a",
+			},
+			ExpStruct:  newPatchTestConfig,
+			ExpErrKeys: []string{"fixed", "fixed.2", "internal", "missing", "nested.after", "nested.flattened", "ports.0", "server.Port", "tags.x"},
+		},
+	}
+
+	for _, curTest := range testSet {
+		t.Run(curTest.Name, func(t *testing.T) {
+			if curTest.SkipTest {
+				t.Skipf("skipped '%s' due to SkipTest being set", curTest.Name)
+			}
+
+			target := newPatchTestConfig()
+			origLabels := target.Labels
+			origServer := target.Servers["primary"]
+
+			err := Patch(&target, curTest.Changes, curTest.PatchOpts...)
+
+			var errKeys []string
+			if err != nil {
+				for _, joined := range err.(interface{ Unwrap() []error }).Unwrap() {
+					var keyErr *KeyError
+					if !errors.As(joined, &keyErr) {
+						t.Fatalf("error is not a *KeyError: %v", joined)
+					}
+					errKeys = append(errKeys, keyErr.Key)
+				}
+			}
+			if !reflect.DeepEqual(errKeys, curTest.ExpErrKeys) {
+				t.Errorf("failed keys not as expected\nHave: %v\nWant: %v\nError: %v", errKeys, curTest.ExpErrKeys, err)
+			}
+
+			if exp := curTest.ExpStruct(); !reflect.DeepEqual(target, exp) {
+				t.Errorf("patched struct not as expected\nHave: %+v\nWant: %+v", target, exp)
+			}
+
+			// the original containers are never modified in place
+			if !reflect.DeepEqual(origLabels, map[string]string{"team": "core", "env": "dev"}) || *origServer != (patchTestServer{Host: "one", Port: 1}) {
+				t.Errorf("patch modified the original containers: %+v %+v", origLabels, origServer)
+			}
+		})
+	}
+}
+
+func Test_PatchRoundTrip(t *testing.T) {
+	orig := newPatchTestConfig()
+	orig.Backup = &patchTestServer{Host: "backup", Timeout: time.Second}
+	orig.Fixed = [2]int{3, 4}
+	orig.Nested.Flattened = true
+	orig.Nested.After = "a"
+	// ConvertStruct keys the fields of structures held by maps without their map key
+	orig.Servers = nil
+	orig.internal = 0
+
+	var res patchTestConfig
+	if err := Patch(&res, ConvertStruct(orig)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(res, orig) {
+		t.Errorf("patched struct not the same as the original\nHave: %+v\nWant: %+v", res, orig)
+	}
+}
+
+func Test_PatchBadTarget(t *testing.T) {
+	if err := Patch(patchTestConfig{}, map[string]any{"name": "x"}); err == nil {
+		t.Errorf("expected an error patching a non-pointer")
+	}
+
+	var nilPtr *patchTestConfig
+	if err := Patch(nilPtr, map[string]any{"name": "x"}); err == nil {
+		t.Errorf("expected an error patching a nil pointer")
+	}
+}
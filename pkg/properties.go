@@ -0,0 +1,291 @@
+package struct2map
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Writes (and expects when reading) INI style output where the first segment of every key becomes a [section]
+// header instead of a key prefix; keys with a single segment are written ahead of the first section
+func PropertiesINI() Option {
+	return optionFunc(func(cfg *convertConfig) {
+		cfg.propertiesINI = true
+	})
+}
+
+// Takes a structure (or any value Convert accepts) and writes it to w in the Java .properties format (or INI format
+// if PropertiesINI is passed) as key=value lines sorted by key; allows passing of various options (see StructConvertOpts
+// constants and the other Option returning functions)
+//
+// Keys and values are escaped as the .properties format requires; =, :, #, ! and whitespace in keys, leading whitespace
+// in values, backslashes and line breaks everywhere, and any character outside of printable ASCII as \uXXXX.
+// Nil values are written as an empty value and values stored whole as a list (ex: arrays) one key per index.
+//
+// Returns: nil on success or an error if obj cannot be converted or writing to w fails
+func WriteProperties(w io.Writer, obj any, opts ...Option) error {
+	cfg := newConvertConfig(opts...)
+
+	flat := convertToMap(cfg, obj)
+	if flat == nil {
+		return fmt.Errorf("struct2map: cannot convert %T", obj)
+	}
+
+	// values stored whole (ex: arrays) are written one key per index, as slices are
+	for k, v := range flat {
+		if items, ok := formatSliceValues(cfg, v); ok {
+			delete(flat, k)
+			for idx, item := range items {
+				flat[fmt.Sprintf("%s.%d", k, idx)] = item
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(flat))
+	for k := range flat {
+		keys = append(keys, k)
+	}
+	sortKeys(keys)
+
+	// INI sections have to be contiguous, so write every key without a section first and then each section in turn
+	if cfg.propertiesINI {
+		var unsectioned, sectioned []string
+		for _, k := range keys {
+			if strings.Contains(k, ".") {
+				sectioned = append(sectioned, k)
+			} else {
+				unsectioned = append(unsectioned, k)
+			}
+		}
+		keys = append(unsectioned, sectioned...)
+	}
+
+	bufW := bufio.NewWriter(w)
+	curSection := ""
+	for _, k := range keys {
+		lineKey := k
+		if cfg.propertiesINI {
+			if section, rest, found := strings.Cut(k, "."); found {
+				if section != curSection {
+					if curSection != "" {
+						bufW.WriteString("\n")
+					}
+					fmt.Fprintf(bufW, "[%s]\n", escapeProperty(section, true))
+					curSection = section
+				}
+				lineKey = rest
+			}
+		}
+
+		fmt.Fprintf(bufW, "%s=%s\n", escapeProperty(lineKey, true), escapeProperty(formatValue(cfg, flat[k]), false))
+	}
+
+	return bufW.Flush()
+}
+
+// Reads the Java .properties (or INI) formatted data from r and populates the structure pointed to by dest with it;
+// allows passing of various options (see StructConvertOpts constants), which must match the options used to write it
+//
+// Any [section] header lines prefix the keys that follow them with the section name, so INI data is read whether
+// or not PropertiesINI is passed. Comment lines start with # or ! (or ; when PropertiesINI is passed) and lines ending
+// in an odd number of backslashes continue onto the next line, or end the data when there is none. Keys are then
+// applied as Patch applies them.
+//
+// Returns: nil on success or an error for malformed input or joining a *KeyError for every key that could not be
+// applied; on error dest is left untouched
+func ReadProperties(r io.Reader, dest any, opts ...Option) error {
+	cfg := newConvertConfig(opts...)
+	changes := make(map[string]any)
+
+	section := ""
+	lineNum := 0
+	// applies one logical line, which is either a [section] header or a key and value
+	addLine := func(line string) error {
+		if line[0] == '[' && strings.HasSuffix(strings.TrimRight(line, " \t\f"), "]") {
+			trimmed := strings.TrimRight(line, " \t\f")
+			unescaped, err := unescapeProperty(trimmed[1 : len(trimmed)-1])
+			if err != nil {
+				return fmt.Errorf("struct2map: line %d: %w", lineNum, err)
+			}
+			section = strings.TrimSpace(unescaped)
+			return nil
+		}
+
+		key, value, err := splitProperty(line)
+		if err != nil {
+			return fmt.Errorf("struct2map: line %d: %w", lineNum, err)
+		}
+		if section != "" {
+			key = fmt.Sprintf("%s.%s", section, key)
+		}
+		changes[key] = value
+		return nil
+	}
+
+	// a bufio.Reader rather than a bufio.Scanner so lines are not limited in length
+	bufR := bufio.NewReader(r)
+	logicalLine := ""
+	for atEOF := false; !atEOF; {
+		raw, err := bufR.ReadString('\n')
+		if err == io.EOF {
+			atEOF = true
+		} else if err != nil {
+			return err
+		}
+		if raw == "" && atEOF {
+			break
+		}
+
+		lineNum++
+		line := strings.TrimLeft(strings.TrimSuffix(strings.TrimSuffix(raw, "\n"), "\r"), " \t\f")
+		isComment := line != "" && (line[0] == '#' || line[0] == '!' || (cfg.propertiesINI && line[0] == ';'))
+		if logicalLine == "" && (line == "" || isComment) {
+			continue
+		}
+
+		// a line ending in an odd number of backslashes continues on the next line
+		trailing := len(line) - len(strings.TrimRight(line, `\`))
+		if trailing%2 == 1 {
+			logicalLine += line[:len(line)-1]
+			continue
+		}
+		line = logicalLine + line
+		logicalLine = ""
+
+		if err := addLine(line); err != nil {
+			return err
+		}
+	}
+	// the last line may still be continued when the data ends
+	if logicalLine != "" {
+		if err := addLine(logicalLine); err != nil {
+			return err
+		}
+	}
+
+	return patchWithDefaults("ReadProperties", dest, changes, opts...)
+}
+
+// splits a logical line on the first unescaped =, : or whitespace and unescapes both sides
+func splitProperty(line string) (key, value string, err error) {
+	sepIdx := len(line)
+	for idx := 0; idx < len(line); idx++ {
+		if line[idx] == '\\' {
+			idx++
+			continue
+		}
+		if strings.IndexByte("=: \t\f", line[idx]) >= 0 {
+			sepIdx = idx
+			break
+		}
+	}
+
+	rest := strings.TrimLeft(line[min(sepIdx, len(line)):], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+
+	if key, err = unescapeProperty(line[:sepIdx]); err != nil {
+		return "", "", err
+	}
+	if value, err = unescapeProperty(rest); err != nil {
+		return "", "", err
+	}
+
+	return key, value, nil
+}
+
+func escapeProperty(str string, isKey bool) string {
+	var sb strings.Builder
+	for idx, char := range str {
+		switch {
+		case char == '\\':
+			sb.WriteString(`\\`)
+		case char == '\n':
+			sb.WriteString(`\n`)
+		case char == '\r':
+			sb.WriteString(`\r`)
+		case char == '\t':
+			sb.WriteString(`\t`)
+		case char == '\f':
+			sb.WriteString(`\f`)
+		case char == '=' || char == ':':
+			sb.WriteRune('\\')
+			sb.WriteRune(char)
+		case (char == '#' || char == '!' || char == ';' || char == '[') && (isKey || idx == 0):
+			sb.WriteRune('\\')
+			sb.WriteRune(char)
+		case char == ' ' && (isKey || idx == 0):
+			sb.WriteString(`\ `)
+		case char < 0x20 || char > 0x7e:
+			if char > 0xffff {
+				high, low := utf16.EncodeRune(char)
+				fmt.Fprintf(&sb, `\u%04x\u%04x`, high, low)
+			} else {
+				fmt.Fprintf(&sb, `\u%04x`, char)
+			}
+		default:
+			sb.WriteRune(char)
+		}
+	}
+
+	return sb.String()
+}
+
+func unescapeProperty(str string) (string, error) {
+	if !strings.Contains(str, `\`) {
+		return str, nil
+	}
+
+	var sb strings.Builder
+	var pendingHigh rune
+	for idx := 0; idx < len(str); idx++ {
+		if str[idx] != '\\' {
+			sb.WriteByte(str[idx])
+			continue
+		}
+
+		idx++
+		if idx >= len(str) {
+			break
+		}
+
+		switch str[idx] {
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'u':
+			if idx+4 >= len(str) {
+				return "", fmt.Errorf("malformed \\u escape in %q", str)
+			}
+			code, err := strconv.ParseUint(str[idx+1:idx+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\u escape in %q", str)
+			}
+			idx += 4
+
+			char := rune(code)
+			switch {
+			case utf16.IsSurrogate(char) && pendingHigh == 0:
+				pendingHigh = char
+				continue
+			case pendingHigh != 0:
+				char = utf16.DecodeRune(pendingHigh, char)
+				pendingHigh = 0
+			}
+			sb.WriteRune(char)
+		default:
+			sb.WriteByte(str[idx])
+		}
+	}
+
+	return sb.String(), nil
+}
@@ -0,0 +1,203 @@
+package struct2map
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type propertiesTestServer struct {
+	Host string `struct2map:"host"`
+	Port int    `struct2map:"port"`
+}
+
+type propertiesTestConfig struct {
+	Name    string                `struct2map:"name"`
+	Note    string                `struct2map:"note"`
+	Server  propertiesTestServer  `struct2map:"server"`
+	Backup  *propertiesTestServer `struct2map:"backup"`
+	Tags    []string              `struct2map:"tags"`
+	Labels  map[string]string     `struct2map:"labels"`
+	Enabled bool                  `struct2map:"enabled"`
+	Pair    [2]string             `struct2map:"pair"`
+}
+
+func Test_WriteProperties(t *testing.T) {
+	testStruct := propertiesTestConfig{
+		Name:    "svc=main: ü",
+		Note:    " leading space, #not a comment\nsecond line 😀",
+		Server:  propertiesTestServer{Host: "localhost", Port: 8080},
+		Tags:    []string{"a", "b"},
+		Labels:  map[string]string{"team key": "core"},
+		Enabled: true,
+		Pair:    [2]string{"x", "y z"},
+	}
+
+	testSet := []struct {
+		Name        string
+		PropOpts    []Option
+		ExpectedOut string
+		SkipTest    bool
+	}{
+		{
+			Name: "properties format",
+			ExpectedOut: strings.Join([]string{
+				`backup=`,
+				`enabled=true`,
+				`labels.team\ key=core`,
+				`name=svc\=main\: \u00fc`,
+				`note=\ leading space, #not a comment\nsecond line \ud83d\ude00`,
+				`pair.0=x`,
+				`pair.1=y z`,
+				`server.host=localhost`,
+				`server.port=8080`,
+				`tags.0=a`,
+				`tags.1=b`,
+			}, "\n") + "\n",
+		},
+		{
+			Name:     "ini format",
+			PropOpts: []Option{PropertiesINI()},
+			ExpectedOut: strings.Join([]string{
+				`backup=`,
+				`enabled=true`,
+				`name=svc\=main\: \u00fc`,
+				`note=\ leading space, #not a comment\nsecond line \ud83d\ude00`,
+				`[labels]`,
+				`team\ key=core`,
+				``,
+				`[pair]`,
+				`0=x`,
+				`1=y z`,
+				``,
+				`[server]`,
+				`host=localhost`,
+				`port=8080`,
+				``,
+				`[tags]`,
+				`0=a`,
+				`1=b`,
+			}, "\n") + "\n",
+		},
+	}
+
+	for _, curTest := range testSet {
+		t.Run(curTest.Name, func(t *testing.T) {
+			if curTest.SkipTest {
+				t.Skipf("skipped '%s' due to SkipTest being set", curTest.Name)
+			}
+
+			var out bytes.Buffer
+			if err := WriteProperties(&out, testStruct, curTest.PropOpts...); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if out.String() != curTest.ExpectedOut {
+				t.Errorf("generated output not the same as the expected output\nHave:\n%s\nWant:\n%s", out.String(), curTest.ExpectedOut)
+			}
+
+			// and everything reads back to where it started
+			var readBack propertiesTestConfig
+			if err := ReadProperties(&out, &readBack, curTest.PropOpts...); err != nil {
+				t.Fatalf("unexpected error reading back: %v", err)
+			}
+			if !reflect.DeepEqual(readBack, testStruct) {
+				t.Errorf("read back value not the same as the written value\nHave: %+v\nWant: %+v", readBack, testStruct)
+			}
+		})
+	}
+
+	if err := WriteProperties(&bytes.Buffer{}, 1); err == nil {
+		t.Errorf("expected an error writing a value that cannot be converted")
+	}
+}
+
+func Test_ReadProperties(t *testing.T) {
+	input := strings.Join([]string{
+		`# a comment`,
+		`! another comment`,
+		`name : spaced out`,
+		`note   multi \`,
+		`       line`,
+		`enabled`,
+		``,
+		`[server]`,
+		`; ini comment`,
+		`host=remote`,
+		`port=bad`,
+	}, "\n")
+
+	var dest propertiesTestConfig
+	err := ReadProperties(strings.NewReader(input), &dest, PropertiesINI())
+	if errKeys := keyErrorKeys(t, err); !reflect.DeepEqual(errKeys, []string{"server.port"}) {
+		t.Fatalf("failed keys not as expected: %v", err)
+	}
+	if !reflect.DeepEqual(dest, propertiesTestConfig{}) {
+		t.Errorf("dest modified despite errors: %+v", dest)
+	}
+
+	input = strings.Replace(input, "port=bad", "port=1", 1)
+	if err := ReadProperties(strings.NewReader(input), &dest, PropertiesINI()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := propertiesTestConfig{Name: "spaced out", Note: "multi line", Server: propertiesTestServer{Host: "remote", Port: 1}}
+	if !reflect.DeepEqual(dest, expected) {
+		t.Errorf("read value not the same as the expected value\nHave: %+v\nWant: %+v", dest, expected)
+	}
+
+	if err := ReadProperties(strings.NewReader(`name=\u12`), &dest); err == nil {
+		t.Errorf("expected an error for a malformed unicode escape")
+	}
+}
+
+func Test_ReadPropertiesLines(t *testing.T) {
+	longNote := strings.Repeat("x", 100*1024)
+
+	testSet := []struct {
+		Name        string
+		Input       string
+		PropOpts    []Option
+		ExpectedVal propertiesTestConfig
+		ExpErrKeys  []string
+		SkipTest    bool
+	}{
+		{
+			Name:       "semicolons only start comments in INI data",
+			Input:      "name=a;b\n;name=c",
+			ExpErrKeys: []string{";name"},
+		},
+		{
+			Name:        "semicolon comments in INI data",
+			Input:       "name=a;b\n;name=c",
+			PropOpts:    []Option{PropertiesINI()},
+			ExpectedVal: propertiesTestConfig{Name: "a;b"},
+		},
+		{
+			Name:        "continuation at the end of the data",
+			Input:       "name=first\nnote=multi \\",
+			ExpectedVal: propertiesTestConfig{Name: "first", Note: "multi "},
+		},
+		{
+			Name:        "lines longer than a scanner buffer",
+			Input:       "note=" + longNote + "\r\nname=last",
+			ExpectedVal: propertiesTestConfig{Name: "last", Note: longNote},
+		},
+	}
+
+	for _, curTest := range testSet {
+		t.Run(curTest.Name, func(t *testing.T) {
+			if curTest.SkipTest {
+				t.Skipf("skipped '%s' due to SkipTest being set", curTest.Name)
+			}
+
+			var dest propertiesTestConfig
+			err := ReadProperties(strings.NewReader(curTest.Input), &dest, curTest.PropOpts...)
+			if errKeys := keyErrorKeys(t, err); !reflect.DeepEqual(errKeys, curTest.ExpErrKeys) {
+				t.Errorf("failed keys not as expected\nHave: %v\nWant: %v\nError: %v", errKeys, curTest.ExpErrKeys, err)
+			}
+			if !reflect.DeepEqual(dest, curTest.ExpectedVal) {
+				t.Errorf("read value not the same as the expected value\nHave: %.80v\nWant: %.80v", dest, curTest.ExpectedVal)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package struct2map
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"reflect"
+
+	"github.com/newodahs/struct2map/internal"
+)
+
+const DEFAULT_REDACT_MASK = "[REDACTED]"
+
+type RedactMode uint
+
+// How redacted values (see the redact tag option and Redact) are replaced; RedactMode values are Options themselves
+const (
+	REDACT_MASK RedactMode = iota // the value is replaced by the mask (see RedactMask); the default
+	REDACT_HASH                   // the value is replaced by the SHA-256 of its string form (ex: sha256:9f86d0...), so equal values can still be correlated
+	REDACT_DROP                   // the key is left out entirely
+)
+
+func (mode RedactMode) apply(cfg *convertConfig) {
+	cfg.redactMode = mode
+}
+
+// Redacts every key matching any of the glob patterns, exactly as if the field were tagged with the redact option;
+// patterns are matched segment by segment against the flattened keys, where * within a segment matches any run of
+// characters, a # segment matches exactly one slice index and a ** segment matches any number of segments
+// (ex: *.Password, Auth.*, **.token, Users.#.Email)
+//
+// May be passed more than once; the patterns add up.
+func Redact(patterns ...string) Option {
+	return optionFunc(func(cfg *convertConfig) {
+		cfg.redactRules = append(cfg.redactRules, patterns...)
+	})
+}
+
+// Sets the mask REDACT_MASK replaces redacted values with (default: DEFAULT_REDACT_MASK)
+func RedactMask(mask string) Option {
+	return optionFunc(func(cfg *convertConfig) {
+		cfg.redactMask = mask
+	})
+}
+
+// true if a redaction rule matches key
+func (cfg *convertConfig) isRedacted(key string) bool {
+	for _, pattern := range cfg.redactRules {
+		if matchKeyGlob(pattern, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// true if key, or a key above it, is redacted when converting a value of type t (by a field tagged with the redact
+// option or a redaction rule); such keys hold the replacement rather than the value, so the reverse conversions skip them
+func isRedactedKey(cfg *convertConfig, t reflect.Type, key string) bool {
+	segs := splitKey(key)
+	for idx := range segs {
+		if cfg.isRedacted(joinKey(segs[:idx+1])) {
+			return true
+		}
+	}
+
+	for idx, seg := range segs {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		switch t.Kind() {
+		case reflect.Struct:
+			fieldPath := findField(cfg, t, seg, idx == 0)
+			for _, pos := range fieldPath {
+				for t.Kind() == reflect.Pointer {
+					t = t.Elem()
+				}
+				if parseFieldTag(cfg, t.Field(pos)).redact {
+					return true
+				}
+				t = t.Field(pos).Type
+			}
+			if fieldPath == nil {
+				return false
+			}
+		case reflect.Map, reflect.Slice, reflect.Array:
+			t = t.Elem()
+		default:
+			return false
+		}
+	}
+
+	return false
+}
+
+// changes without the keys isRedactedKey reports for t; changes itself if there are none
+func withoutRedactedKeys(cfg *convertConfig, t reflect.Type, changes map[string]any) map[string]any {
+	var ret map[string]any
+	for key := range changes {
+		if !isRedactedKey(cfg, t, key) {
+			continue
+		}
+
+		if ret == nil {
+			ret = make(map[string]any, len(changes))
+			for k, v := range changes {
+				ret[k] = v
+			}
+		}
+		delete(ret, key)
+	}
+
+	if ret == nil {
+		return changes
+	}
+	return ret
+}
+
+// stores the replacement for a redacted value at keyName (if any); the value is only ever read whole, never walked
+func redactToMap(cfg *convertConfig, dest map[string]any, keyName string, workingField reflect.Value) {
+	switch cfg.redactMode {
+	case REDACT_DROP:
+		return
+	case REDACT_HASH:
+		dest[keyName] = fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(internal.ConvertValueToString(workingField))))
+	default:
+		if cfg.redactMask == "" {
+			dest[keyName] = DEFAULT_REDACT_MASK
+			return
+		}
+		dest[keyName] = cfg.redactMask
+	}
+}
@@ -0,0 +1,159 @@
+package struct2map
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type redactTestAuth struct {
+	User     string `struct2map:"user"`
+	Password string `struct2map:"password,redact"`
+}
+
+type redactTestConfig struct {
+	Auth    redactTestAuth    `struct2map:"auth"`
+	Admin   redactTestAuth    `struct2map:"admin"`
+	Token   *string           `struct2map:"token,redact"`
+	Secrets map[string]string `struct2map:"secrets"`
+	Inner   struct{ Key, Name string }
+	Deep    map[string]any     `struct2map:"deep"`
+	Nodes   []redactTestAuth   `struct2map:"nodes"`
+	Skipped *redactTestConfig  `struct2map:"-"`
+	Hooks   map[string]*string `struct2map:"hooks"`
+}
+
+func Test_Redact(t *testing.T) {
+	token := "t0k3n"
+	testConfig := redactTestConfig{
+		Auth:    redactTestAuth{User: "u", Password: "p"},
+		Admin:   redactTestAuth{User: "root", Password: "r"},
+		Token:   &token,
+		Secrets: map[string]string{"db": "x", "api": "y"},
+		Inner:   struct{ Key, Name string }{Key: "k", Name: "n"},
+		Deep:    map[string]any{"a": map[string]any{"token": "z", "keep": 1}},
+		Nodes:   []redactTestAuth{{User: "n0", Password: "np"}},
+	}
+	hashOf := func(str string) string { return fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(str))) }
+
+	testSet := []struct {
+		Name        string
+		ConvertOpts []Option
+		ExpectedMap map[string]any
+		SkipTest    bool
+	}{
+		{
+			Name: "tag option masks by default",
+			ExpectedMap: map[string]any{
+				"auth.user": "u", "auth.password": DEFAULT_REDACT_MASK, "admin.user": "root", "admin.password": DEFAULT_REDACT_MASK,
+				"token": DEFAULT_REDACT_MASK, "secrets.db": "x", "secrets.api": "y", "Inner.Key": "k", "Inner.Name": "n",
+				"deep.a.token": "z", "deep.a.keep": 1, "nodes.0.user": "n0", "nodes.0.password": DEFAULT_REDACT_MASK,
+			},
+		},
+		{
+			Name:        "path rules with a custom mask",
+			ConvertOpts: []Option{Redact("secrets.*", "Inner"), Redact("**.token"), RedactMask("***")},
+			ExpectedMap: map[string]any{
+				"auth.user": "u", "auth.password": "***", "admin.user": "root", "admin.password": "***",
+				"token": "***", "secrets.db": "***", "secrets.api": "***", "Inner": "***",
+				"deep.a.token": "***", "deep.a.keep": 1, "nodes.0.user": "n0", "nodes.0.password": "***",
+			},
+		},
+		{
+			Name:        "hashed",
+			ConvertOpts: []Option{REDACT_HASH, Redact("*.user")},
+			ExpectedMap: map[string]any{
+				"auth.user": hashOf("u"), "auth.password": hashOf("p"), "admin.user": hashOf("root"), "admin.password": hashOf("r"),
+				"token": hashOf("t0k3n"), "secrets.db": "x", "secrets.api": "y", "Inner.Key": "k", "Inner.Name": "n",
+				"deep.a.token": "z", "deep.a.keep": 1, "nodes.0.user": "n0", "nodes.0.password": hashOf("np"),
+			},
+		},
+		{
+			Name:        "dropped",
+			ConvertOpts: []Option{REDACT_DROP, Redact("deep.**", "nodes.*.*")},
+			ExpectedMap: map[string]any{
+				"auth.user": "u", "admin.user": "root", "secrets.db": "x", "secrets.api": "y", "Inner.Key": "k", "Inner.Name": "n",
+			},
+		},
+		{
+			Name:        "values stored whole at MaxDepth are still redacted",
+			ConvertOpts: []Option{MaxDepth(1), Redact("deep.a.token")},
+			ExpectedMap: map[string]any{
+				"auth":    map[string]any{"user": "u", "password": DEFAULT_REDACT_MASK},
+				"admin":   map[string]any{"user": "root", "password": DEFAULT_REDACT_MASK},
+				"token":   DEFAULT_REDACT_MASK,
+				"secrets": testConfig.Secrets,
+				"Inner":   testConfig.Inner,
+				"deep":    map[string]any{"a": map[string]any{"token": DEFAULT_REDACT_MASK, "keep": 1}},
+				"nodes":   []any{map[string]any{"user": "n0", "password": DEFAULT_REDACT_MASK}},
+				"hooks":   testConfig.Hooks,
+			},
+		},
+	}
+
+	for _, curTest := range testSet {
+		t.Run(curTest.Name, func(t *testing.T) {
+			if curTest.SkipTest {
+				t.Skipf("skipped '%s' due to SkipTest being set", curTest.Name)
+			}
+
+			genMap := Convert(testConfig, curTest.ConvertOpts...)
+			if !reflect.DeepEqual(genMap, curTest.ExpectedMap) {
+				t.Errorf("generated map not the same as the expected map\nHave: %v\nWant: %v", genMap, curTest.ExpectedMap)
+			}
+		})
+	}
+}
+
+func Test_MatchKeyGlob(t *testing.T) {
+	testSet := []struct {
+		Pattern  string
+		Key      string
+		Expected bool
+	}{
+		{Pattern: "*.Password", Key: "Admin.Password", Expected: true},
+		{Pattern: "*.Password", Key: "Password", Expected: false},
+		{Pattern: "*.Password", Key: "A.B.Password", Expected: false},
+		{Pattern: "**.Password", Key: "Password", Expected: true},
+		{Pattern: "**.Password", Key: "A.B.Password", Expected: true},
+		{Pattern: "Auth.*", Key: "Auth", Expected: false},
+		{Pattern: "Auth.**", Key: "Auth", Expected: true},
+		{Pattern: "Auth.**", Key: "Auth.x.y", Expected: true},
+		{Pattern: "A.**.z", Key: "A.b.c.z", Expected: true},
+		{Pattern: "*Token", Key: "apiToken", Expected: true},
+		{Pattern: "api*en", Key: "apiTok", Expected: false},
+		{Pattern: "a*b*c", Key: "aXbYc", Expected: true},
+		{Pattern: "a*b*c", Key: "acb", Expected: false},
+	}
+
+	for _, curTest := range testSet {
+		t.Run(fmt.Sprintf("%s~%s", curTest.Pattern, curTest.Key), func(t *testing.T) {
+			if matched := matchKeyGlob(curTest.Pattern, curTest.Key); matched != curTest.Expected {
+				t.Errorf("unexpected match result %v", matched)
+			}
+		})
+	}
+}
+
+type redactTestNode struct {
+	Name   string          `struct2map:"name"`
+	Secret string          `struct2map:"secret,redact"`
+	Next   *redactTestNode `struct2map:"next"`
+}
+
+func Test_RedactMaxDepthLoop(t *testing.T) {
+	loop := &redactTestNode{Name: "a", Secret: "s"}
+	loop.Next = loop
+
+	// rebuilding a value that refers back to itself must stop rather than walk forever
+	genMap := Convert(loop, MaxDepth(1))
+	expected := map[string]any{
+		"name":   "a",
+		"secret": DEFAULT_REDACT_MASK,
+		"next":   map[string]any{"name": "a", "secret": DEFAULT_REDACT_MASK, "next": map[string]any{"name": "a", "secret": DEFAULT_REDACT_MASK}},
+	}
+	if !reflect.DeepEqual(genMap, expected) {
+		t.Errorf("generated map not the same as the expected map\nHave: %v\nWant: %v", genMap, expected)
+	}
+}
@@ -0,0 +1,199 @@
+package struct2map
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/newodahs/struct2map/internal"
+)
+
+// The key segments Schema uses in place of the parts of a key only a value can tell; keys holding them are valid glob
+// patterns (see Include, Exclude and Redact) matching the keys they stand for
+const (
+	SCHEMA_SLICE_INDEX = "#"  // any slice (or array) index
+	SCHEMA_MAP_KEY     = "*"  // any map key
+	SCHEMA_RECURSIVE   = "**" // anything below a structure that contains itself
+)
+
+// Describes a key (or family of keys) a type can produce when converted
+type KeySpec struct {
+	Key       string       // the key, with wildcard segments for slice indexes and map keys (ex: Tags.#, Labels.*)
+	Type      reflect.Type // the type of the value stored at the key (string for redacted fields)
+	Desc      string       // the desc tag of the field the key comes from, if any
+	Enum      []string     // the allowed values, from the enum tag (ex: enum:"debug|info|warn") of the field the key comes from
+	Min       string       // the min tag of the field the key comes from, if any; the lowest number or the shortest string allowed
+	Max       string       // the max tag of the field the key comes from, if any; the highest number or the longest string allowed
+	Nullable  bool         // the key can hold nil (ex: a nil pointer not tagged omitempty)
+	OmitEmpty bool         // the field the key comes from is tagged omitempty; nil values are left out
+	Required  bool         // the field the key comes from (or a structure field it is found within, unless omitempty) is tagged required
+	Label     bool         // the field the key comes from is tagged label
+	Redacted  bool         // the value is redacted (see the redact tag option); with REDACT_DROP the key is never output
+	Groups    []string     // the groups the key is output for (see Groups); nil if it is always output
+	Recursive bool         // the key ends in SCHEMA_RECURSIVE; Type contains itself and is not walked again, or is an interface
+}
+
+// the type redacted values are replaced with
+var redactedType = reflect.TypeOf("")
+
+// walks a type the same way valueToMap walks a value, collecting a KeySpec for every leaf
+type schemaWalker struct {
+	cfg        *convertConfig
+	specs      []KeySpec
+	inProgress map[reflect.Type]bool // guards against recursive types
+}
+
+// Takes a structure type (either a reflect.Type or a value of the type, zero or not) and lists every key converting
+// a value of it can produce; allows passing of various options (see StructConvertOpts constants and the other Option
+// returning functions) which shape the keys as they would for ConvertStruct or Convert
+//
+// The keys are listed in field order. Slice indexes are listed as SCHEMA_SLICE_INDEX and map keys as SCHEMA_MAP_KEY
+// (ex: Tags.#, Labels.*, Servers.#.Host); structures containing themselves are listed once, as a key ending in
+// SCHEMA_RECURSIVE. Values held in interfaces can be anything; they are listed with their interface type, as
+// ConvertStruct stores them, and as a key ending in SCHEMA_RECURSIVE for what Convert flattens out of them. Maps and
+// slices held by map entries and slice items are listed both whole, as ConvertStruct stores them, and walked, as
+// Convert does (ex: Matrix.# and Matrix.#.#). Pointers not tagged omitempty are listed at their own key as well, as a
+// nil pointer is stored there (ex: Backup along with Backup.Host).
+//
+// The Groups option and the redact tag option are honored; the options matching keys against patterns (Include,
+// Exclude and Redact) are not, as they match the keys of a value rather than these.
+//
+// Returns: []KeySpec describing the keys or nil if the type is not a structure (or a pointer to one)
+func Schema(typeOrValue any, opts ...Option) []KeySpec {
+	t, ok := typeOrValue.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(typeOrValue)
+	}
+	if t == nil {
+		return nil
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	w := &schemaWalker{cfg: newConvertConfig(opts...), inProgress: map[reflect.Type]bool{}}
+	w.walkStruct(t, nil, KeySpec{})
+
+	return w.specs
+}
+
+// walks the fields of a structure type; base holds the tag information inherited by the keys within it
+func (w *schemaWalker) walkStruct(t reflect.Type, parentSegs []string, base KeySpec) {
+	if w.inProgress[t] {
+		base.Key = joinKey(appendSeg(parentSegs, SCHEMA_RECURSIVE))
+		base.Type = t
+		base.Recursive = true
+		w.specs = append(w.specs, base)
+		return
+	}
+	w.inProgress[t] = true
+	defer delete(w.inProgress, t)
+
+	fieldTags := cachedFieldTags(t)
+	for pos := 0; pos < t.NumField(); pos++ {
+		tag := configureFieldTag(w.cfg, fieldTags[pos])
+		if tag.skip {
+			continue
+		}
+
+		// as in structToMap, ignoring parents drops the prefix for this field and every field after it
+		if tag.ignoreParents {
+			parentSegs = nil
+		}
+
+		// a field without groups of its own belongs to the groups of its structure
+		groups := base.Groups
+		if tag.groups != nil {
+			groups = tag.groups
+		}
+		if !w.cfg.inActiveGroup(groups) {
+			continue
+		}
+
+		field := t.Field(pos)
+		spec := KeySpec{
+			Desc:      field.Tag.Get(internal.STRUCT_MAP_DESC_TAGNAME),
+			Min:       field.Tag.Get(internal.STRUCT_MAP_MIN_TAGNAME),
+			Max:       field.Tag.Get(internal.STRUCT_MAP_MAX_TAGNAME),
+			OmitEmpty: tag.omitEmpty,
+			Required:  tag.required || (base.Required && !tag.omitEmpty), // the fields of a required structure are required with it
+			Label:     tag.label,
+			Groups:    groups,
+		}
+		if enum, ok := field.Tag.Lookup(internal.STRUCT_MAP_ENUM_TAGNAME); ok {
+			spec.Enum = strings.Split(enum, "|")
+		}
+
+		segs := appendSeg(parentSegs, fieldKeyName(w.cfg, tag))
+		if tag.redact {
+			spec.Key = joinKey(segs)
+			spec.Type = redactedType
+			spec.Redacted = true
+			w.specs = append(w.specs, spec)
+			continue
+		}
+
+		w.walk(field.Type, segs, spec, tag.omitEmpty, false)
+	}
+}
+
+// walks the type of a value stored at segs; omitEmpty and isItem as valueToMap takes them
+func (w *schemaWalker) walk(t reflect.Type, segs []string, spec KeySpec, omitEmpty, isItem bool) {
+	isPointer := t.Kind() == reflect.Pointer
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		spec.Nullable = !omitEmpty
+	}
+	if t.Kind() == reflect.Interface {
+		spec.Nullable = !omitEmpty
+	}
+
+	// as valueToMap does, arrays are only walked at the root
+	isContainer := t.Kind() == reflect.Struct || t.Kind() == reflect.Map || t.Kind() == reflect.Slice ||
+		(t.Kind() == reflect.Array && len(segs) == 0)
+	cut := w.cfg.maxDepth > 0 && len(segs) >= w.cfg.maxDepth
+	storedWhole := !isContainer || t == timeType || cut ||
+		(w.cfg.keepSlices && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && isScalarType(t.Elem()))
+	if storedWhole {
+		spec.Key = joinKey(segs)
+		spec.Type = t
+		w.specs = append(w.specs, spec)
+
+		// Convert flattens whatever an interface holds
+		if t.Kind() == reflect.Interface && !cut {
+			w.specs = append(w.specs, KeySpec{Key: joinKey(appendSeg(segs, SCHEMA_RECURSIVE)), Type: t, Groups: spec.Groups, Recursive: true})
+		}
+		return
+	}
+
+	// a nil pointer is stored at its own key, while ConvertStruct stores the containers held by map entries and slice
+	// items whole where Convert walks them
+	if (isPointer && spec.Nullable) || (isItem && t.Kind() != reflect.Struct) {
+		whole := spec
+		whole.Key = joinKey(segs)
+		whole.Type = t
+		w.specs = append(w.specs, whole)
+	}
+
+	base := KeySpec{Groups: spec.Groups, Required: spec.Required}
+	switch t.Kind() {
+	case reflect.Struct:
+		w.walkStruct(t, segs, base)
+	case reflect.Map:
+		elem := t.Elem()
+		if elem.Kind() == reflect.Pointer {
+			elem = elem.Elem()
+		}
+
+		// as valueToMap does, structures held in maps are keyed without the map key
+		if elem.Kind() == reflect.Struct && elem != timeType {
+			w.walkStruct(elem, segs, base)
+			return
+		}
+		w.walk(t.Elem(), appendSeg(segs, SCHEMA_MAP_KEY), spec, false, true)
+	case reflect.Slice, reflect.Array:
+		w.walk(t.Elem(), appendSeg(segs, SCHEMA_SLICE_INDEX), spec, false, true)
+	}
+}
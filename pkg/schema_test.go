@@ -0,0 +1,209 @@
+package struct2map
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type schemaTestServer struct {
+	Host string `struct2map:"host" desc:"the host name"`
+	Port uint16 `struct2map:"port,required"`
+}
+
+type schemaTestNode struct {
+	Name     string
+	Children []schemaTestNode
+	Parent   *schemaTestNode `struct2map:"Parent,omitempty"`
+}
+
+type schemaTestList struct {
+	Name string
+	Next *schemaTestList
+}
+
+type schemaTestConfig struct {
+	Name     string                      `struct2map:"name,required" desc:"service name"`
+	Server   schemaTestServer            `struct2map:"server"`
+	Backups  []*schemaTestServer         `struct2map:"backups,omitempty"`
+	Servers  map[string]schemaTestServer `struct2map:"servers"`
+	Tags     []string                    `struct2map:"tags"`
+	Labels   map[string]string           `struct2map:"labels,label"`
+	Matrix   [][2]float64                `struct2map:"matrix"`
+	Started  time.Time                   `struct2map:"started"`
+	Password string                      `struct2map:"password,redact"`
+	Debug    bool                        `struct2map:"debug,groups=dev"`
+	Extra    any                         `struct2map:"extra"`
+	Tree     schemaTestNode              `struct2map:"tree"`
+	Level    string                      `struct2map:"level" enum:"debug|info|warn"`
+	Retries  *int                        `struct2map:"retries" min:"0" max:"10"`
+	Flat     string                      `struct2map:"flat,ignoreparents"`
+	Skipped  string                      `struct2map:"-"`
+	internal int
+}
+
+var (
+	stringType  = reflect.TypeOf("")
+	uint16Type  = reflect.TypeOf(uint16(0))
+	float64Type = reflect.TypeOf(float64(0))
+	anyType     = reflect.TypeOf((*any)(nil)).Elem()
+)
+
+func Test_Schema(t *testing.T) {
+	serverHost := KeySpec{Type: stringType, Desc: "the host name"}
+	serverPort := KeySpec{Type: uint16Type, Required: true}
+	withKey := func(spec KeySpec, key string) KeySpec {
+		spec.Key = key
+		return spec
+	}
+
+	fullSchema := []KeySpec{
+		{Key: "name", Type: stringType, Desc: "service name", Required: true},
+		withKey(serverHost, "server.host"),
+		withKey(serverPort, "server.port"),
+		{Key: "backups.#", Type: reflect.TypeOf(schemaTestServer{}), Nullable: true, OmitEmpty: true},
+		withKey(serverHost, "backups.#.host"),
+		withKey(serverPort, "backups.#.port"),
+		withKey(serverHost, "servers.host"),
+		withKey(serverPort, "servers.port"),
+		{Key: "tags.#", Type: stringType},
+		{Key: "labels.*", Type: stringType, Label: true},
+		{Key: "matrix.#", Type: reflect.TypeOf([2]float64{})},
+		{Key: "started", Type: reflect.TypeOf(time.Time{})},
+		{Key: "password", Type: stringType, Redacted: true},
+		{Key: "debug", Type: reflect.TypeOf(false), Groups: []string{"dev"}},
+		{Key: "extra", Type: anyType, Nullable: true},
+		{Key: "extra.**", Type: anyType, Recursive: true},
+		{Key: "tree.Name", Type: stringType},
+		{Key: "tree.Children.#.**", Type: reflect.TypeOf(schemaTestNode{}), Recursive: true},
+		{Key: "tree.Parent.**", Type: reflect.TypeOf(schemaTestNode{}), Recursive: true},
+		{Key: "level", Type: stringType, Enum: []string{"debug", "info", "warn"}},
+		{Key: "retries", Type: reflect.TypeOf(0), Min: "0", Max: "10", Nullable: true},
+		{Key: "flat", Type: stringType},
+	}
+
+	testSet := []struct {
+		Name     string
+		Input    any
+		Opts     []Option
+		Expected []KeySpec
+		SkipTest bool
+	}{
+		{
+			Name:     "zero value",
+			Input:    schemaTestConfig{},
+			Expected: fullSchema,
+		},
+		{
+			Name:     "reflect type of a pointer",
+			Input:    reflect.TypeOf(&schemaTestConfig{}),
+			Expected: fullSchema,
+		},
+		{
+			Name:  "options shape the keys",
+			Input: schemaTestServer{},
+			Opts:  []Option{STRUCT_CONVERT_MAPKEY_TOUPPER, UnexportedFields("_")},
+			Expected: []KeySpec{
+				withKey(serverHost, "HOST"),
+				withKey(serverPort, "PORT"),
+			},
+		},
+		{
+			Name:  "groups",
+			Input: schemaTestConfig{},
+			Opts:  []Option{Groups("ops")},
+			Expected: func() []KeySpec {
+				var ret []KeySpec
+				for _, spec := range fullSchema {
+					if spec.Key != "debug" {
+						ret = append(ret, spec)
+					}
+				}
+				return ret
+			}(),
+		},
+		{
+			Name: "max depth and kept slices",
+			Input: struct {
+				Server schemaTestServer
+				Tags   []string
+				Nested struct{ Tags []int }
+			}{},
+			Opts: []Option{MaxDepth(1), KeepSlices()},
+			Expected: []KeySpec{
+				{Key: "Server", Type: reflect.TypeOf(schemaTestServer{})},
+				{Key: "Tags", Type: reflect.TypeOf([]string{})},
+				{Key: "Nested", Type: reflect.TypeOf(struct{ Tags []int }{})},
+			},
+		},
+		{
+			Name: "nil pointers, interfaces and required structures",
+			Input: struct {
+				Backup *schemaTestServer `struct2map:"backup,required"`
+				Extra  any               `struct2map:"extra"`
+				Head   schemaTestList    `struct2map:"head"`
+				Port   int               `struct2map:"port"`
+			}{},
+			Expected: []KeySpec{
+				{Key: "backup", Type: reflect.TypeOf(schemaTestServer{}), Nullable: true, Required: true},
+				{Key: "backup.host", Type: stringType, Desc: "the host name", Required: true},
+				{Key: "backup.port", Type: uint16Type, Required: true},
+				{Key: "extra", Type: anyType, Nullable: true},
+				{Key: "extra.**", Type: anyType, Recursive: true},
+				{Key: "head.Name", Type: stringType},
+				{Key: "head.Next", Type: reflect.TypeOf(schemaTestList{}), Nullable: true},
+				{Key: "head.Next.**", Type: reflect.TypeOf(schemaTestList{}), Recursive: true},
+				{Key: "port", Type: reflect.TypeOf(0)},
+			},
+		},
+		{
+			Name:  "not a structure",
+			Input: map[string]int{},
+		},
+		{
+			Name: "nil",
+		},
+	}
+
+	for _, test := range testSet {
+		t.Run(test.Name, func(t *testing.T) {
+			if test.SkipTest {
+				t.Skipf("skipping test")
+			}
+
+			res := Schema(test.Input, test.Opts...)
+			if !reflect.DeepEqual(res, test.Expected) {
+				t.Fatalf("result mismatch\nexpected: %+v\ngot:      %+v", test.Expected, res)
+			}
+		})
+	}
+}
+
+// every key ConvertStruct (or Convert) produces must match one of the Schema keys
+func Test_SchemaCoversConvert(t *testing.T) {
+	cfg := schemaTestConfig{
+		Name:    "svc",
+		Backups: []*schemaTestServer{{Host: "a"}, {Host: "b"}},
+		Servers: map[string]schemaTestServer{"primary": {Host: "c"}},
+		Tags:    []string{"x", "y"},
+		Labels:  map[string]string{"team": "core"},
+		Matrix:  [][2]float64{{1, 2}},
+		Tree:    schemaTestNode{Name: "root", Children: []schemaTestNode{{Name: "child", Children: []schemaTestNode{{Name: "grandchild"}}}}},
+	}
+
+	schema := Schema(cfg)
+	for _, genMap := range []map[string]any{ConvertStruct(cfg), Convert(cfg)} {
+		for key := range genMap {
+			matched := false
+			for _, spec := range schema {
+				if matchKeyGlob(spec.Key, key) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				t.Errorf("key %s is not covered by the schema", key)
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package struct2map
+
+import (
+	"log/slog"
+)
+
+// Has Log produce flat attributes keyed by the full flattened key (ex: server.port=8080) instead of nested groups
+// (ex: server.port=8080 in a text handler, but {"server":{"port":8080}} in a JSON handler)
+func LogFlat() Option {
+	return optionFunc(func(cfg *convertConfig) {
+		cfg.logFlat = true
+	})
+}
+
+// Wraps a value for logging with log/slog; see Log
+type LogValue struct {
+	obj  any
+	opts []Option
+}
+
+// Takes a structure (or any value Convert accepts) and wraps it so log/slog logs it as its flattened keys rather than
+// as a single opaque value (ex: slog.Info("request", "req", struct2map.Log(req))); allows passing of various options
+// (see StructConvertOpts constants, MaxDepth and LogFlat)
+//
+// The conversion happens when the record is handled, so it is skipped entirely for disabled log levels. Tag options
+// (ex: omitempty, "-") and redaction apply as they do for Convert, including within values MaxDepth stores whole.
+//
+// Returns: the LogValue wrapping obj
+func Log(obj any, opts ...Option) LogValue {
+	return LogValue{obj: obj, opts: opts}
+}
+
+// Satisfies slog.LogValuer; a group of every flattened key, nested by segment unless LogFlat was passed.
+// Values that cannot be converted are logged as they are.
+func (lv LogValue) LogValue() slog.Value {
+	cfg := newConvertConfig(lv.opts...)
+
+	flat := convertToMap(cfg, lv.obj)
+	if flat == nil {
+		return slog.AnyValue(lv.obj)
+	}
+
+	keys := make([]string, 0, len(flat))
+	for k := range flat {
+		keys = append(keys, k)
+	}
+	sortKeys(keys)
+
+	if cfg.logFlat {
+		attrs := make([]slog.Attr, 0, len(keys))
+		for _, k := range keys {
+			attrs = append(attrs, slog.Any(k, flat[k]))
+		}
+		return slog.GroupValue(attrs...)
+	}
+
+	return slog.GroupValue(logAttrs(flat, keys, 0)...)
+}
+
+// builds the attributes for the segment at depth of the given (sorted) keys, grouping keys sharing that segment;
+// sorting keeps every key sharing a segment together
+func logAttrs(flat map[string]any, keys []string, depth int) []slog.Attr {
+	var ret []slog.Attr
+	for start := 0; start < len(keys); {
+		segs := splitKey(keys[start])
+		if len(segs) == depth+1 {
+			ret = append(ret, slog.Any(segs[depth], flat[keys[start]]))
+			start++
+			continue
+		}
+
+		end := start + 1
+		for ; end < len(keys); end++ {
+			nextSegs := splitKey(keys[end])
+			if len(nextSegs) <= depth+1 || nextSegs[depth] != segs[depth] {
+				break
+			}
+		}
+
+		ret = append(ret, slog.Attr{Key: segs[depth], Value: slog.GroupValue(logAttrs(flat, keys[start:end], depth+1)...)})
+		start = end
+	}
+
+	return ret
+}
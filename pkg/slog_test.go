@@ -0,0 +1,90 @@
+package struct2map
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+type slogTestRequest struct {
+	Method  string            `struct2map:"method"`
+	Server  envTestServer     `struct2map:"server"`
+	Headers map[string]string `struct2map:"headers"`
+	Token   string            `struct2map:"-"`
+	Trace   *envTestServer    `struct2map:"trace,omitempty"`
+}
+
+func Test_Log(t *testing.T) {
+	testReq := slogTestRequest{
+		Method:  "GET",
+		Server:  envTestServer{Host: "localhost", Port: 8080},
+		Headers: map[string]string{"accept": "json"},
+		Token:   "secret",
+	}
+
+	testSet := []struct {
+		Name        string
+		LogValue    any
+		JSON        bool
+		ExpectedLog string
+		SkipTest    bool
+	}{
+		{
+			Name:        "nested groups",
+			LogValue:    Log(testReq),
+			JSON:        true,
+			ExpectedLog: `{"msg":"req","r":{"headers":{"accept":"json"},"method":"GET","server":{"host":"localhost","port":8080}}}`,
+		},
+		{
+			Name:        "flat attributes",
+			LogValue:    Log(&testReq, LogFlat()),
+			JSON:        true,
+			ExpectedLog: `{"msg":"req","r":{"headers.accept":"json","method":"GET","server.host":"localhost","server.port":8080}}`,
+		},
+		{
+			Name:        "text handler and max depth",
+			LogValue:    Log(testReq, MaxDepth(1)),
+			ExpectedLog: `msg=req r.headers=map[accept:json] r.method=GET r.server="{Host:localhost Port:8080}"`,
+		},
+		{
+			Name: "redaction holds at max depth",
+			LogValue: Log(struct {
+				Auth redactTestAuth `struct2map:"auth"`
+			}{Auth: redactTestAuth{User: "u", Password: "hunter2"}}, MaxDepth(1)),
+			JSON:        true,
+			ExpectedLog: `{"msg":"req","r":{"auth":{"password":"[REDACTED]","user":"u"}}}`,
+		},
+		{
+			Name:        "values that cannot be converted",
+			LogValue:    Log(42),
+			ExpectedLog: `msg=req r=42`,
+		},
+	}
+
+	for _, curTest := range testSet {
+		t.Run(curTest.Name, func(t *testing.T) {
+			if curTest.SkipTest {
+				t.Skipf("skipped '%s' due to SkipTest being set", curTest.Name)
+			}
+
+			// drop the time and level so the output is stable
+			var buf bytes.Buffer
+			handlerOpts := &slog.HandlerOptions{ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == slog.LevelKey) {
+					return slog.Attr{}
+				}
+				return a
+			}}
+			var handler slog.Handler = slog.NewTextHandler(&buf, handlerOpts)
+			if curTest.JSON {
+				handler = slog.NewJSONHandler(&buf, handlerOpts)
+			}
+
+			slog.New(handler).Info("req", "r", curTest.LogValue)
+			if genLog := strings.TrimSpace(buf.String()); genLog != curTest.ExpectedLog {
+				t.Errorf("logged output not the same as the expected output\nHave: %s\nWant: %s", genLog, curTest.ExpectedLog)
+			}
+		})
+	}
+}
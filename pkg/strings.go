@@ -0,0 +1,61 @@
+package struct2map
+
+import (
+	"reflect"
+)
+
+// Sets the strconv.FormatFloat format ('g', 'e', 'E', 'f', ...) floats (and complex numbers) are formatted with by the
+// text based encoders (default: 'g'); the precision is always the smallest that parses back to the exact same value
+func FloatFormat(format byte) Option {
+	return optionFunc(func(cfg *convertConfig) {
+		cfg.stringFormat.FloatFormat = format
+	})
+}
+
+// Sets the layout time.Time values are formatted with by the text based encoders (default: time.RFC3339Nano); only
+// layouts that keep the full time (ex: time.RFC3339Nano, time.RFC3339 for whole seconds) parse back to the same value
+func TimeLayout(layout string) Option {
+	return optionFunc(func(cfg *convertConfig) {
+		cfg.stringFormat.TimeLayout = layout
+	})
+}
+
+// Sets the strings bools are formatted as by the text based encoders (default: "true" and "false"); only the forms
+// strconv.ParseBool accepts (ex: "1" and "0", "t" and "f") parse back to the same value
+func BoolStrings(trueStr string, falseStr string) Option {
+	return optionFunc(func(cfg *convertConfig) {
+		cfg.stringFormat.TrueString = trueStr
+		cfg.stringFormat.FalseString = falseStr
+	})
+}
+
+// Sets the string nil values are formatted as by the text based encoders (default: the empty string)
+func NilString(nilStr string) Option {
+	return optionFunc(func(cfg *convertConfig) {
+		cfg.stringFormat.NilString = nilStr
+	})
+}
+
+// Takes a structure (obj) and turns it into a single, flat map of strings; allows passing of various options (see
+// StructConvertOpts constants, FloatFormat, TimeLayout, BoolStrings, NilString and the other Option returning functions)
+//
+// The keys are exactly those ConvertStruct produces. With the default formatting every value parses back (ex: with
+// Patch or FromEnv) to the value it was formatted from: integers in base 10, floats in the shortest form that is
+// exact, bools as true and false, durations as time.Duration.String and times as time.RFC3339Nano.
+//
+// Returns: map[string]string that is representative of the passed structure or nil on error (ex: not a struct passed)
+func ConvertStructToStrings(obj any, opts ...Option) map[string]string {
+	cfg := newConvertConfig(opts...)
+
+	flat := structToMap(cfg, "", reflect.ValueOf(obj))
+	if flat == nil {
+		return nil
+	}
+
+	ret := make(map[string]string, len(flat))
+	for k, v := range flat {
+		ret[k] = formatValue(cfg, v)
+	}
+
+	return ret
+}
@@ -0,0 +1,171 @@
+package struct2map
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type stringsTestStruct struct {
+	Name     string         `struct2map:"name"`
+	Count    int            `struct2map:"count"`
+	Small    int8           `struct2map:"small"`
+	Big      uint64         `struct2map:"big"`
+	Ratio    float64        `struct2map:"ratio"`
+	Ratio32  float32        `struct2map:"ratio32"`
+	Complex  complex128     `struct2map:"complex"`
+	Enabled  bool           `struct2map:"enabled"`
+	Disabled bool           `struct2map:"disabled"`
+	Timeout  time.Duration  `struct2map:"timeout"`
+	Started  time.Time      `struct2map:"started"`
+	Tags     []string       `struct2map:"tags"`
+	Extra    *int           `struct2map:"extra"`
+	Limits   map[string]int `struct2map:"limits"`
+}
+
+func newStringsTestStruct() stringsTestStruct {
+	return stringsTestStruct{
+		Name:     "svc",
+		Count:    -42,
+		Small:    math.MinInt8,
+		Big:      math.MaxUint64,
+		Ratio:    math.Nextafter(0.3, 1),
+		Ratio32:  1.1,
+		Complex:  complex(1.5, -2),
+		Enabled:  true,
+		Timeout:  90 * time.Second,
+		Started:  time.Date(2024, 2, 29, 13, 4, 5, 123456789, time.FixedZone("", -5*60*60)),
+		Tags:     []string{"a", "b"},
+		Limits:   map[string]int{"cpu": 2},
+		Disabled: false,
+	}
+}
+
+func Test_ConvertStructToStrings(t *testing.T) {
+	testSet := []struct {
+		Name     string
+		Opts     []Option
+		Expected map[string]string
+		SkipTest bool
+	}{
+		{
+			Name: "default formatting",
+			Expected: map[string]string{
+				"name":       "svc",
+				"count":      "-42",
+				"small":      "-128",
+				"big":        "18446744073709551615",
+				"ratio":      "0.30000000000000004",
+				"ratio32":    "1.1",
+				"complex":    "(1.5-2i)",
+				"enabled":    "true",
+				"disabled":   "false",
+				"timeout":    "1m30s",
+				"started":    "2024-02-29T13:04:05.123456789-05:00",
+				"tags.0":     "a",
+				"tags.1":     "b",
+				"extra":      "",
+				"limits.cpu": "2",
+			},
+		},
+		{
+			Name: "custom formatting",
+			Opts: []Option{FloatFormat('e'), TimeLayout(time.DateOnly), BoolStrings("yes", "no"), NilString("null"), STRUCT_CONVERT_MAPKEY_TOUPPER},
+			Expected: map[string]string{
+				"NAME":       "svc",
+				"COUNT":      "-42",
+				"SMALL":      "-128",
+				"BIG":        "18446744073709551615",
+				"RATIO":      "3.0000000000000004e-01",
+				"RATIO32":    "1.1e+00",
+				"COMPLEX":    "(1.5e+00-2e+00i)",
+				"ENABLED":    "yes",
+				"DISABLED":   "no",
+				"TIMEOUT":    "1m30s",
+				"STARTED":    "2024-02-29",
+				"TAGS.0":     "a",
+				"TAGS.1":     "b",
+				"EXTRA":      "null",
+				"LIMITS.CPU": "2",
+			},
+		},
+	}
+
+	for _, test := range testSet {
+		t.Run(test.Name, func(t *testing.T) {
+			if test.SkipTest {
+				t.Skipf("skipping test")
+			}
+
+			res := ConvertStructToStrings(newStringsTestStruct(), test.Opts...)
+			if !reflect.DeepEqual(res, test.Expected) {
+				t.Fatalf("result mismatch\nexpected: %v\ngot:      %v", test.Expected, res)
+			}
+		})
+	}
+
+	if res := ConvertStructToStrings([]int{1}); res != nil {
+		t.Fatalf("expected nil for a non structure, got %v", res)
+	}
+}
+
+func Test_ConvertStructToStringsRoundTrip(t *testing.T) {
+	testSet := []struct {
+		Name     string
+		Opts     []Option
+		SkipTest bool
+	}{
+		{
+			Name: "default formatting",
+		},
+		{
+			Name: "exponent floats and numeric bools",
+			Opts: []Option{FloatFormat('e'), BoolStrings("1", "0")},
+		},
+		{
+			Name: "fixed floats",
+			Opts: []Option{FloatFormat('f')},
+		},
+	}
+
+	for _, test := range testSet {
+		t.Run(test.Name, func(t *testing.T) {
+			if test.SkipTest {
+				t.Skipf("skipping test")
+			}
+
+			orig := newStringsTestStruct()
+			changes := map[string]any{}
+			for k, v := range ConvertStructToStrings(orig, test.Opts...) {
+				changes[k] = v
+			}
+
+			var res stringsTestStruct
+			if err := Patch(&res, changes, test.Opts...); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !res.Started.Equal(orig.Started) {
+				t.Fatalf("time mismatch\nexpected: %v\ngot:      %v", orig.Started, res.Started)
+			}
+			res.Started = orig.Started
+			if !reflect.DeepEqual(res, orig) {
+				t.Fatalf("result mismatch\nexpected: %+v\ngot:      %+v", orig, res)
+			}
+		})
+	}
+}
+
+func Test_TimeLeaf(t *testing.T) {
+	started := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	res := ConvertStruct(struct {
+		Started  time.Time
+		Finished *time.Time `struct2map:",omitempty"`
+		Events   map[string]time.Time
+	}{Started: started, Events: map[string]time.Time{"boot": started}})
+
+	expected := map[string]any{"Started": started, "Events.boot": started}
+	if !reflect.DeepEqual(res, expected) {
+		t.Fatalf("result mismatch\nexpected: %v\ngot:      %v", expected, res)
+	}
+}
@@ -3,7 +3,9 @@ package struct2map
 import (
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/iancoleman/strcase"
 	"github.com/newodahs/struct2map/internal"
@@ -31,37 +33,121 @@ const (
 // see README documentation for further notes on this
 //
 // Additional structure tag options include omitempty to omit nil-able fields from the map
-// and ignoreparents to ignore the prior parent namespace prefixes at that point
+// and ignoreparents to ignore the prior parent namespace prefixes at that point;
+// default=value outputs value in place of a zero (or nil) field (a default that cannot be parsed is ignored here but
+// reported by ConvertOf)
 //
 // Returns: map[string]any that is representative of the passed structure or nil on error (ex: empty struct passed; not a struct passed)
 func ConvertStruct(obj any, opts ...StructConvertOpts) map[string]any {
-	var nameMod func(string) string
+	cfg := &convertConfig{}
+	for _, opt := range opts {
+		opt.apply(cfg)
+	}
+
+	return structToMap(cfg, "", reflect.ValueOf(obj))
+}
+
+// satisfies the Option interface so the StructConvertOpts constants can be passed anywhere an Option is accepted
+func (opt StructConvertOpts) apply(cfg *convertConfig) {
+	switch opt {
+	case STRUCT_CONVERT_MAPKEY_TOLOWER:
+		cfg.nameModFunc = strings.ToLower
+	case STRUCT_CONVERT_MAPKEY_TOUPPER:
+		cfg.nameModFunc = strings.ToUpper
+	case STRUCT_CONVERT_MAPKEY_CAMELCASE:
+		cfg.nameModFunc = strcase.ToCamel
+	case STRUCT_CONVERT_MAPKEY_LOWERCAMEL:
+		cfg.nameModFunc = strcase.ToLowerCamel
+	case STRUCT_CONVERT_MAPKEY_SNAKE:
+		cfg.nameModFunc = strcase.ToSnake
+	}
+}
 
+// Takes a structure, map, slice or array (obj) and turns it into a single, flat map; allows passing of various options
+// (see StructConvertOpts constants and the other Option returning functions)
+//
+// Structures are converted as ConvertStruct does, except that whatever interfaces hold and the containers within map
+// entries and slice items (ex: decoded JSON) are flattened too rather than stored whole. For maps, each (converted to
+// string) map key becomes the top level key (ex: primary.Port for a map[string]Config) and for slices and arrays each
+// index does (ex: 0.Host); arrays below the root are stored whole, as ConvertStruct stores them.
+//
+// Returns: map[string]any that is representative of the passed value or nil on error (ex: nil passed; a value that is not a structure or container passed)
+func Convert(obj any, opts ...Option) map[string]any {
+	return convertToMap(newConvertConfig(opts...), obj)
+}
+
+// Takes a value and converts it into dest under key exactly as ConvertStruct converts a structure field (tagged with
+// omitempty if omitEmpty is set) holding it; allows passing of various options (see StructConvertOpts constants)
+//
+// This is the reflective fallback the code generated by struct2map-gen uses for the field types it does not
+// convert directly; there is little reason to call it otherwise. Interface fields are passed by address (ex:
+// &v.Extra), as a value passed as an any is no longer known to have been held by an interface.
+func ConvertValueInto(dest map[string]any, key string, value any, omitEmpty bool, opts ...StructConvertOpts) {
+	cfg := &convertConfig{}
 	for _, opt := range opts {
-		switch opt {
-		case STRUCT_CONVERT_MAPKEY_TOLOWER:
-			nameMod = strings.ToLower
-		case STRUCT_CONVERT_MAPKEY_TOUPPER:
-			nameMod = strings.ToUpper
-		case STRUCT_CONVERT_MAPKEY_CAMELCASE:
-			nameMod = strcase.ToCamel
-		case STRUCT_CONVERT_MAPKEY_LOWERCAMEL:
-			nameMod = strcase.ToLowerCamel
-		case STRUCT_CONVERT_MAPKEY_SNAKE:
-			nameMod = strcase.ToSnake
-		}
+		opt.apply(cfg)
 	}
 
-	return structToMap(nameMod, "", obj)
+	valueToMap(cfg, dest, key, reflect.ValueOf(value), omitEmpty, false)
 }
 
-func structToMap(nameModFunc func(string) string, parentName string, obj any) map[string]any {
+// Includes unexported structure fields in the output, keyed by their field (or tag) name prefixed with marker
+// (ex: "_" turns the field secret into the key _secret); applies to nested and embedded structures as well.
+//
+// Unexported fields are only ever read, never written; the reverse conversions (ex: Patch) ignore them.
+func UnexportedFields(marker string) Option {
+	return optionFunc(func(cfg *convertConfig) {
+		cfg.includeUnexported = true
+		cfg.unexportedMarker = marker
+	})
+}
+
+// Limits the flattened keys to depth segments; structures, maps and slices nested any deeper are stored whole at the
+// key they were found at (ex: with a depth of 1, Server.Port is not flattened and the key Server holds the Server
+// structure itself). A depth of 0 (the default) means no limit.
+//
+// A value with anything redacted, filtered out (see Include and Exclude) or in no selected group (see Groups) within it
+// is stored as the nested tree of its keys instead (see Unflatten), with those rules applied.
+func MaxDepth(depth int) Option {
+	return optionFunc(func(cfg *convertConfig) {
+		cfg.maxDepth = depth
+	})
+}
+
+func convertToMap(cfg *convertConfig, obj any) map[string]any {
 	if obj == nil {
 		return nil
 	}
 
+	cfg.nestedContainers = true
+
 	objValue := reflect.ValueOf(obj)
+	for objValue.Kind() == reflect.Pointer || objValue.Kind() == reflect.Interface {
+		objValue = objValue.Elem()
+	}
 
+	switch objValue.Kind() {
+	case reflect.Struct:
+		return structToMap(cfg, "", objValue)
+	case reflect.Map:
+		ret := make(map[string]any)
+		mapItr := objValue.MapRange()
+		for mapItr.Next() {
+			valueToMap(cfg, ret, mapSubKey(cfg, mapItr.Key()), mapItr.Value(), false, false)
+		}
+		return ret
+	case reflect.Slice, reflect.Array:
+		ret := make(map[string]any)
+		for idx := 0; idx < objValue.Len(); idx++ {
+			valueToMap(cfg, ret, strconv.Itoa(idx), objValue.Index(idx), false, false)
+		}
+		return ret
+	}
+
+	return nil
+}
+
+func structToMap(cfg *convertConfig, parentName string, objValue reflect.Value) map[string]any {
 	for {
 		if objValue.Kind() == reflect.Pointer {
 			objValue = objValue.Elem()
@@ -74,100 +160,268 @@ func structToMap(nameModFunc func(string) string, parentName string, obj any) ma
 		return nil
 	}
 	ret := make(map[string]any)
-	objType := objValue.Type()
+	fieldTags := cachedFieldTags(objValue.Type())
 
 	//rip over each structure member and process it into the map
-STRUCT_MEMBER_PROC:
 	for pos := 0; pos < objValue.NumField(); pos++ {
-		if !objType.Field(pos).IsExported() {
-			continue STRUCT_MEMBER_PROC
-		}
-
-		//proc the field tags (if any)
-		omitempty := false
-		ignoreParents := false
-		actualFieldName := objType.Field(pos).Name
-		mapKeyName, ok := objType.Field(pos).Tag.Lookup(internal.STRUCT_MAP_PRIMARY_TAGNAME)
-		if !ok {
-			mapKeyName = actualFieldName //no tag, just take the field name
-		} else {
-			//proc the tag information
-			fieldSplit := strings.Split(mapKeyName, ",")
-			mapKeyName = fieldSplit[0] //fieldname is always pos 0 for us...
-
-			// field should not be exported; ignore everything else after that as it's moot
-			if mapKeyName == "-" {
-				continue STRUCT_MEMBER_PROC
-			}
-
-			for fIdx, fVal := range fieldSplit {
-				if fIdx < 1 {
-					continue
-				}
-
-				switch fVal {
-				case internal.STRUCT_MAP_TAG_IGNORE_PARENT:
-					ignoreParents = true
-				case internal.STRUCT_MAP_TAG_OMIT:
-					omitempty = true
-				}
-			}
-
-			// before we go, reset our key name to the actual field name if modifier function was passed to us...
-			// we do this here because we have to process other tags (ignoreparents, omitemtpy) even when a modifier
-			// is passed...
-			if nameModFunc != nil {
-				mapKeyName = actualFieldName
-			}
+		tag := configureFieldTag(cfg, fieldTags[pos])
+		if tag.skip {
+			continue
 		}
 
 		// if we have a parent name, prepend it here (if not ignored)
-		if ignoreParents {
+		if tag.ignoreParents {
 			parentName = ""
 		}
 
-		fieldToMap(ret, parentName, mapKeyName, objValue.Field(pos), omitempty, nameModFunc)
+		// zero fields are output as their default, if they have one
+		field := objValue.Field(pos)
+		if tag.defaultValue.IsValid() && field.IsZero() {
+			field = copyDefault(tag.defaultValue)
+		}
+
+		fieldToMap(cfg, ret, parentName, tag, field)
 	}
 
 	return ret
 }
 
-const DEFAULT_SUBKEY_STRING = "emptyKey"
+// the processed struct2map tag (or lack thereof) of a single structure field
+type fieldTag struct {
+	name          string // map key name for the field, before any name modifier is applied
+	goName        string // name of the field in Go
+	skip          bool   // field is tagged as "-" or is not exported (and unexported fields were not asked for)
+	unexported    bool   // field is not exported; it can be read (see UnexportedFields) but never written
+	omitEmpty     bool
+	ignoreParents bool
+	required      bool
+	label         bool
+	redact        bool
+	groups        []string // nil if the field does not name any groups itself
+	hasDefault    bool
+	defaultValue  reflect.Value // the parsed default; invalid if there is none or it did not parse
+	defaultErr    error         // set if the default did not parse into the field type
+}
 
-func fieldToMap(dest map[string]any, parentKeyName, mapKeyName string, workingField reflect.Value, omitEmpty bool, nameModFunc func(string) string) {
-	for {
-		if workingField.Kind() == reflect.Pointer {
-			if omitEmpty && workingField.IsNil() {
-				return
+func parseFieldTag(cfg *convertConfig, field reflect.StructField) fieldTag {
+	return configureFieldTag(cfg, parseRawFieldTag(field))
+}
+
+// the structure tags of every field of a structure type, as parseRawFieldTag returns them; cached per type since
+// they never change
+var fieldTagCache sync.Map // reflect.Type => []fieldTag
+
+func cachedFieldTags(structType reflect.Type) []fieldTag {
+	if cached, ok := fieldTagCache.Load(structType); ok {
+		return cached.([]fieldTag)
+	}
+
+	tags := make([]fieldTag, structType.NumField())
+	for pos := range tags {
+		tags[pos] = parseRawFieldTag(structType.Field(pos))
+	}
+
+	fieldTagCache.Store(structType, tags)
+	return tags
+}
+
+// the parts of the field tag processing that do not depend on the options passed
+func parseRawFieldTag(field reflect.StructField) fieldTag {
+	unexported := !field.IsExported()
+	actualFieldName := field.Name
+	mapKeyName, ok := field.Tag.Lookup(internal.STRUCT_MAP_PRIMARY_TAGNAME)
+	if !ok {
+		return fieldTag{name: actualFieldName, goName: actualFieldName, unexported: unexported} //no tag, just take the field name
+	}
+
+	//proc the tag information
+	fieldSplit := strings.Split(mapKeyName, ",")
+	ret := fieldTag{name: fieldSplit[0], goName: actualFieldName, unexported: unexported} //fieldname is always pos 0 for us...
+
+	// field should not be exported; ignore everything else after that as it's moot
+	if ret.name == "-" {
+		return fieldTag{skip: true}
+	}
+
+	for _, fVal := range fieldSplit[1:] {
+		switch fVal {
+		case internal.STRUCT_MAP_TAG_IGNORE_PARENT:
+			ret.ignoreParents = true
+		case internal.STRUCT_MAP_TAG_OMIT:
+			ret.omitEmpty = true
+		case internal.STRUCT_MAP_TAG_REQUIRED:
+			ret.required = true
+		case internal.STRUCT_MAP_TAG_LABEL:
+			ret.label = true
+		case internal.STRUCT_MAP_TAG_REDACT:
+			ret.redact = true
+		default:
+			optName, optVal, found := strings.Cut(fVal, "=")
+			if !found {
+				continue
+			}
+
+			switch optName {
+			case internal.STRUCT_MAP_TAG_GROUPS:
+				ret.groups = strings.Split(optVal, "|")
+			case internal.STRUCT_MAP_TAG_DEFAULT:
+				ret.hasDefault = true
+				if defaultVal, err := parseDefault(field.Type, optVal); err != nil {
+					ret.defaultErr = fmt.Errorf("%w %q: %w", ErrInvalidDefault, optVal, err)
+				} else {
+					ret.defaultValue = defaultVal
+				}
 			}
-			workingField = workingField.Elem()
-			continue
 		}
-		break
 	}
 
-	// if we were passed a valid name modifying function, call it upfront
-	keyName := mapKeyName
-	if nameModFunc != nil {
-		keyName = nameModFunc(mapKeyName)
+	return ret
+}
+
+// applies the options passed to a field tag from parseRawFieldTag
+func configureFieldTag(cfg *convertConfig, tag fieldTag) fieldTag {
+	if tag.unexported && !cfg.includeUnexported {
+		return fieldTag{skip: true}
+	}
+
+	// before we go, reset our key name to the actual field name if modifier function was passed to us...
+	// we do this here because we have to process other tags (ignoreparents, omitemtpy) even when a modifier
+	// is passed...
+	if !tag.skip && cfg.nameModFunc != nil {
+		tag.name = tag.goName
 	}
 
+	return tag
+}
+
+const DEFAULT_SUBKEY_STRING = "emptyKey"
+
+func fieldToMap(cfg *convertConfig, dest map[string]any, parentKeyName string, tag fieldTag, workingField reflect.Value) {
+	// if we were passed a valid name modifying function, call it upfront (this also adds any unexported marker)
+	keyName := fieldKeyName(cfg, tag)
+
 	// setup the actual keyname if there is a parent
 	if parentKeyName != "" {
 		keyName = fmt.Sprintf("%s.%s", parentKeyName, keyName)
 	}
 
+	// fields that do not name groups themselves are in the groups of the field containing them
+	parentGroups := cfg.inheritedGroups
+	groups := parentGroups
+	if tag.groups != nil {
+		groups = tag.groups
+	}
+	if !cfg.inActiveGroup(groups) {
+		return
+	}
+	cfg.inheritedGroups = groups
+	defer func() { cfg.inheritedGroups = parentGroups }()
+
+	if tag.label && cfg.metricsLabels != nil {
+		cfg.metricsLabels[keyName] = true
+	}
+
+	// redacted fields are replaced whole; nothing within them is ever traversed
+	if tag.redact {
+		if cfg.filterKey(keyName) == keyIn {
+			redactToMap(cfg, dest, keyName, workingField)
+		}
+		return
+	}
+
+	valueToMap(cfg, dest, keyName, workingField, tag.omitEmpty, false)
+}
+
+// stores the value under the (fully namespaced) keyName, recursing into any structures and containers; isItem is set
+// for map entries and slice items
+func valueToMap(cfg *convertConfig, dest map[string]any, keyName string, workingField reflect.Value, omitEmpty, isItem bool) {
+	// excluded subtrees are pruned here, before anything within them is walked
+	filter := cfg.filterKey(keyName)
+	if filter == keyOut {
+		return
+	}
+	partial := filter == keyPartial // only keys below this one can be included; the value itself is never stored
+
+	// checked here rather than in fieldToMap alone so the rules also reach map entries and slice items
+	if cfg.isRedacted(keyName) {
+		if !partial {
+			redactToMap(cfg, dest, keyName, workingField)
+		}
+		return
+	}
+
+	for workingField.Kind() == reflect.Pointer || workingField.Kind() == reflect.Interface {
+		if omitEmpty && workingField.IsNil() {
+			return
+		}
+		if workingField.Kind() == reflect.Interface && !cfg.nestedContainers {
+			break
+		}
+
+		// values rebuilt by wholeToMap can refer back to themselves; the walk of them stops where they do
+		if cfg.wholePath != nil && workingField.Kind() == reflect.Pointer && !workingField.IsNil() {
+			visit := visitedValue{ptr: workingField.Pointer(), typ: workingField.Type()}
+			if cfg.wholePath[visit] {
+				return
+			}
+			cfg.wholePath[visit] = true
+			defer delete(cfg.wholePath, visit)
+		}
+
+		workingField = workingField.Elem()
+	}
+
 	if !workingField.IsValid() {
-		if !omitEmpty {
+		if !omitEmpty && !partial {
 			dest[keyName] = nil
 		}
 		return
 	}
 
+	// arrays are only walked at the root (see Convert); unless nested containers were asked for, interfaces and the
+	// maps and slices held by map entries and slice items are stored whole too, as ConvertStruct always has
+	whole := workingField.Kind() == reflect.Array
+	if !cfg.nestedContainers {
+		switch workingField.Kind() {
+		case reflect.Interface:
+			whole = true
+		case reflect.Map, reflect.Slice:
+			whole = isItem
+		}
+	}
+
+	// containers nested deeper than MaxDepth allows are stored whole
+	if cfg.maxDepth > 0 && len(splitKey(keyName)) >= cfg.maxDepth {
+		switch workingField.Kind() {
+		case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+			whole = true
+		}
+	}
+
+	if whole {
+		wholeToMap(cfg, dest, keyName, workingField, partial)
+		return
+	}
+
+	containerToMap(cfg, dest, keyName, workingField, omitEmpty, partial)
+}
+
+// walks a single level of the value (pointers already followed) at keyName, storing it if it is not a container
+func containerToMap(cfg *convertConfig, dest map[string]any, keyName string, workingField reflect.Value, omitEmpty, partial bool) {
 	switch workingField.Kind() {
+	case reflect.Interface:
+		valueToMap(cfg, dest, keyName, workingField.Elem(), omitEmpty, false)
 	case reflect.Struct:
+		// times are values in their own right, not structures to walk
+		if workingField.Type() == timeType {
+			if !partial {
+				dest[keyName] = valueInterface(workingField)
+			}
+			return
+		}
+
 		// start the process on a new struct
-		for k, v := range structToMap(nameModFunc, keyName, workingField.Interface()) {
+		for k, v := range structToMap(cfg, keyName, workingField) {
 			dest[k] = v
 		}
 	case reflect.Map:
@@ -182,53 +436,228 @@ func fieldToMap(dest map[string]any, parentKeyName, mapKeyName string, workingFi
 				mapVal = mapVal.Elem()
 			}
 
-			if mapVal.Kind() == reflect.Struct {
-				for k, v := range structToMap(nameModFunc, keyName, mapVal.Interface()) {
+			if mapVal.Kind() == reflect.Struct && mapVal.Type() != timeType {
+				for k, v := range structToMap(cfg, keyName, mapVal) {
 					dest[k] = v
 				}
 			} else {
-				needBrkt := false
-				subKey := internal.ConvertAnyToString(mapItr.Key().Interface())
-				if subKey == "" {
-					subKey = DEFAULT_SUBKEY_STRING
-					needBrkt = true
-				}
-				if nameModFunc != nil {
-					subKey = nameModFunc(subKey)
-				}
-
-				if needBrkt {
-					dest[fmt.Sprintf("%s.[%s]", keyName, subKey)] = mapVal.Interface()
-				} else {
-					dest[fmt.Sprintf("%s.%s", keyName, subKey)] = mapVal.Interface()
-				}
+				valueToMap(cfg, dest, fmt.Sprintf("%s.%s", keyName, mapSubKey(cfg, mapItr.Key())), mapVal, false, true)
 			}
 		}
-	case reflect.Slice:
-		if omitEmpty && workingField.IsNil() {
+	case reflect.Slice, reflect.Array:
+		if omitEmpty && workingField.Kind() == reflect.Slice && workingField.IsNil() {
+			return
+		}
+
+		// some consumers want slices of plain values whole rather than one key per index
+		if cfg.keepSlices && isScalarType(workingField.Type().Elem()) {
+			if !partial {
+				dest[keyName] = valueInterface(workingField)
+			}
 			return
 		}
 
 		for idx := 0; idx < workingField.Len(); idx++ {
-			sliceValue := workingField.Index(idx)
-			if sliceValue.Kind() == reflect.Pointer {
-				sliceValue = sliceValue.Elem()
+			valueToMap(cfg, dest, fmt.Sprintf("%s.%d", keyName, idx), workingField.Index(idx), false, true)
+		}
+	default:
+		if !partial {
+			dest[keyName] = valueInterface(workingField)
+		}
+	}
+}
+
+// stores the container v whole at keyName; should walking v redact, filter out or hide anything within it, the nested
+// tree (as Unflatten builds it) of the keys the walk produces is stored instead, so a value stored whole never
+// bypasses a redaction, an Include/Exclude pattern or the selected Groups
+func wholeToMap(cfg *convertConfig, dest map[string]any, keyName string, v reflect.Value, partial bool) {
+	// only some keys below a partially included one are kept, so it is always walked
+	if !partial && !cfg.walkAltersBelow(keyName, v) {
+		dest[keyName] = valueInterface(v)
+		return
+	}
+
+	depth, path := cfg.maxDepth, cfg.wholePath
+	cfg.maxDepth = 0
+	if path == nil {
+		cfg.wholePath = map[visitedValue]bool{}
+	}
+	walked := make(map[string]any)
+	containerToMap(cfg, walked, keyName, v, false, partial)
+	cfg.maxDepth, cfg.wholePath = depth, path
+
+	below := make(map[string]any, len(walked))
+	for k, val := range walked {
+		if !keyHasPrefix(k, keyName) {
+			dest[k] = val // fields ignoring their parents are keyed outside of v
+			continue
+		}
+		below[k] = val
+	}
+	if len(below) == 0 {
+		return
+	}
+
+	tree, err := unflatten(cfg, below)
+	if err != nil {
+		// keys that cannot be nested (ex: map keys holding a dot) are stored as the walk produced them
+		for k, val := range below {
+			dest[k] = val
+		}
+		return
+	}
+
+	var node any = tree
+	for _, seg := range splitKey(keyName) {
+		switch level := node.(type) {
+		case map[string]any:
+			node = level[seg]
+		case []any:
+			idx, _ := parseSliceIndex(seg)
+			node = level[idx]
+		}
+	}
+	dest[keyName] = node
+}
+
+// true if walking v, found at keyName, would store anything below keyName differently than v holds it: a redaction
+// rule or Exclude pattern could match a key below it or a field within it is tagged redact (or is in no group
+// selected by Groups)
+func (cfg *convertConfig) walkAltersBelow(keyName string, v reflect.Value) bool {
+	segs := splitKey(keyName)
+	for _, rules := range [][]string{cfg.redactRules, cfg.excludeRules} {
+		for _, pattern := range rules {
+			if matchGlobPrefix(splitKey(pattern), segs) {
+				return true
 			}
+		}
+	}
 
-			innerSliceName := fmt.Sprintf("%s.%d", keyName, idx)
-			if sliceValue.Kind() == reflect.Struct {
-				for k, v := range structToMap(nameModFunc, innerSliceName, sliceValue.Interface()) {
-					dest[k] = v
-				}
-			} else {
-				dest[innerSliceName] = sliceValue.Interface()
+	return cfg.holdsAlteredField(v, map[visitedValue]bool{})
+}
+
+// a pointer (or container) already looked into by holdsAlteredField; values can refer back to themselves
+type visitedValue struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// true if v holds, anywhere within it, a structure field the walk would not store as it is
+func (cfg *convertConfig) holdsAlteredField(v reflect.Value, visited map[visitedValue]bool) bool {
+	if !v.IsValid() || isScalarType(v.Type()) {
+		return false
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return false
+		}
+
+		visit := visitedValue{ptr: v.Pointer(), typ: v.Type()}
+		if visited[visit] {
+			return false
+		}
+		visited[visit] = true
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return cfg.holdsAlteredField(v.Elem(), visited)
+	case reflect.Struct:
+		fieldTags := cachedFieldTags(v.Type())
+		for pos := range fieldTags {
+			tag := fieldTags[pos]
+			if tag.redact || !cfg.inActiveGroup(tag.groups) || cfg.holdsAlteredField(v.Field(pos), visited) {
+				return true
 			}
 		}
-	default:
-		if omitEmpty && workingField.Kind() == reflect.Interface && workingField.IsNil() {
-			return
+	case reflect.Map:
+		if isScalarType(v.Type().Elem()) {
+			return false
+		}
+
+		mapItr := v.MapRange()
+		for mapItr.Next() {
+			if cfg.holdsAlteredField(mapItr.Value(), visited) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if isScalarType(v.Type().Elem()) {
+			return false
 		}
 
-		dest[keyName] = workingField.Interface()
+		for idx := 0; idx < v.Len(); idx++ {
+			if cfg.holdsAlteredField(v.Index(idx), visited) {
+				return true
+			}
+		}
 	}
+
+	return false
+}
+
+// the key segment a map key is output as
+func mapSubKey(cfg *convertConfig, mapKey reflect.Value) string {
+	needBrkt := false
+	subKey := internal.ConvertValueToString(mapKey)
+	if subKey == "" {
+		subKey = DEFAULT_SUBKEY_STRING
+		needBrkt = true
+	}
+	if cfg.nameModFunc != nil {
+		subKey = cfg.nameModFunc(subKey)
+	}
+
+	if needBrkt {
+		return fmt.Sprintf("[%s]", subKey)
+	}
+
+	return subKey
+}
+
+// Returns the value held by v as an any; values read through unexported fields cannot be handed out by reflect
+// directly so basic kinds are copied into a fresh value of the same type while anything else is described as a string
+func valueInterface(v reflect.Value) any {
+	if v.CanInterface() {
+		return v.Interface()
+	}
+
+	ret := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Bool:
+		ret.SetBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		ret.SetInt(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		ret.SetUint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		ret.SetFloat(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		ret.SetComplex(v.Complex())
+	case reflect.String:
+		ret.SetString(v.String())
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+
+	return ret.Interface()
+}
+
+// true for the types (and pointers to them) that are always stored as a single value
+func isScalarType(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return true
+	}
+
+	return false
 }
@@ -2,6 +2,7 @@ package struct2map
 
 import (
 	"log"
+	"reflect"
 	"testing"
 )
 
@@ -643,3 +644,186 @@ func Test_MapKeyOptions(t *testing.T) {
 		})
 	}
 }
+
+// test case set for non-structure values passed at the root
+func Test_ConvertRoots(t *testing.T) {
+	simpleInt := 1
+
+	type endpoint struct {
+		Host string
+		Port int `struct2map:"port"`
+	}
+
+	var iface any = map[string]int{"a": 1}
+
+	testSet := []struct {
+		Name          string
+		TestStructure any
+		ExpectedMap   map[string]any
+		ConvertOpts   []Option
+		SkipTest      bool
+	}{
+		{
+			Name:          "map of structs",
+			TestStructure: map[string]endpoint{"primary": {Host: "one", Port: 1}, "backup": {Host: "two", Port: 2}},
+			ExpectedMap:   map[string]any{"primary.Host": "one", "primary.port": 1, "backup.Host": "two", "backup.port": 2},
+		},
+		{
+			Name:          "slice of struct pointers",
+			TestStructure: []*endpoint{{Host: "one", Port: 1}, nil},
+			ExpectedMap:   map[string]any{"0.Host": "one", "0.port": 1, "1": nil},
+		},
+		{
+			Name:          "pointer to array",
+			TestStructure: &[2]int{simpleInt, 2},
+			ExpectedMap:   map[string]any{"0": 1, "1": 2},
+		},
+		{
+			Name:          "pointer to interface holding a map",
+			TestStructure: &iface,
+			ExpectedMap:   map[string]any{"a": 1},
+		},
+		{
+			Name:          "map with pointer keys and name modifier",
+			TestStructure: map[*string]endpoint{nil: {Host: "x"}},
+			ConvertOpts:   []Option{STRUCT_CONVERT_MAPKEY_TOUPPER},
+			ExpectedMap:   map[string]any{"[EMPTYKEY].HOST": "x", "[EMPTYKEY].PORT": 0},
+		},
+		{
+			Name:          "structs behave as ConvertStruct",
+			TestStructure: endpoint{Host: "one"},
+			ExpectedMap:   map[string]any{"Host": "one", "port": 0},
+		},
+		{
+			Name:          "array fields are stored whole",
+			TestStructure: struct{ Pair [2]string }{Pair: [2]string{"a", "b"}},
+			ExpectedMap:   map[string]any{"Pair": [2]string{"a", "b"}},
+		},
+		{
+			Name:          "arrays held by root containers are stored whole",
+			TestStructure: map[string][2]int{"a": {1, 2}},
+			ExpectedMap:   map[string]any{"a": [2]int{1, 2}},
+		},
+		{
+			Name:          "max depth stores deeper containers whole",
+			TestStructure: map[string]any{"a": map[string]any{"b": []int{1}, "c": 2}, "d": endpoint{Host: "one"}},
+			ConvertOpts:   []Option{MaxDepth(2)},
+			ExpectedMap:   map[string]any{"a.b": []int{1}, "a.c": 2, "d.Host": "one", "d.port": 0},
+		},
+		{
+			Name:          "max depth of one",
+			TestStructure: struct{ Server endpoint }{Server: endpoint{Host: "one"}},
+			ConvertOpts:   []Option{MaxDepth(1)},
+			ExpectedMap:   map[string]any{"Server": endpoint{Host: "one"}},
+		},
+		{
+			Name:          "scalars are not converted",
+			TestStructure: simpleInt,
+		},
+	}
+
+	for _, curTest := range testSet {
+		t.Run(curTest.Name, func(t *testing.T) {
+			if curTest.SkipTest {
+				t.Skipf("skipped '%s' due to SkipTest being set", curTest.Name)
+			}
+
+			genMap := Convert(curTest.TestStructure, curTest.ConvertOpts...)
+			if !reflect.DeepEqual(genMap, curTest.ExpectedMap) {
+				t.Errorf("generated map not the same as the expected map\nHave: %+v\nWant: %+v", genMap, curTest.ExpectedMap)
+			}
+		})
+	}
+}
+
+type unexportedInner struct {
+	Visible bool
+	hidden  string
+}
+
+type unexportedTestStruct struct {
+	unexportedInner
+	Exported   int
+	counter    uint8
+	tagged     float64 `struct2map:"taggedName"`
+	negated    int     `struct2map:"-"`
+	ptr        *int
+	labels     map[string]int
+	list       []unexportedInner
+	callback   func()
+	customKind unexportedKind
+}
+
+type unexportedKind int
+
+// test case set for the opt-in inclusion of unexported fields
+func Test_UnexportedFields(t *testing.T) {
+	simpleInt := 1
+	testStruct := unexportedTestStruct{
+		unexportedInner: unexportedInner{Visible: true, hidden: "inner"},
+		Exported:        2,
+		counter:         3,
+		tagged:          4.5,
+		negated:         5,
+		ptr:             &simpleInt,
+		labels:          map[string]int{"a": 6},
+		list:            []unexportedInner{{hidden: "listed"}},
+		customKind:      7,
+	}
+
+	testSet := []struct {
+		Name          string
+		TestStructure any
+		ExpectedMap   map[string]any
+		ConvertOpts   []Option
+		SkipTest      bool
+	}{
+		{
+			Name:          "unexported fields are skipped by default",
+			TestStructure: testStruct,
+			ExpectedMap:   map[string]any{"Exported": 2},
+		},
+		{
+			Name:          "unexported fields included with a marker",
+			TestStructure: &testStruct,
+			ConvertOpts:   []Option{UnexportedFields("_")},
+			ExpectedMap: map[string]any{
+				"_unexportedInner.Visible": true,
+				"_unexportedInner._hidden": "inner",
+				"Exported":                 2,
+				"_counter":                 uint8(3),
+				"_taggedName":              4.5,
+				"_ptr":                     1,
+				"_labels.a":                6,
+				"_list.0.Visible":          false,
+				"_list.0._hidden":          "listed",
+				"_callback":                "<nil>",
+				"_customKind":              unexportedKind(7),
+			},
+		},
+		{
+			Name:          "marker is added after name modifiers",
+			TestStructure: unexportedInner{hidden: "x"},
+			ConvertOpts:   []Option{UnexportedFields("~"), STRUCT_CONVERT_MAPKEY_CAMELCASE},
+			ExpectedMap:   map[string]any{"Visible": false, "~Hidden": "x"},
+		},
+	}
+
+	for _, curTest := range testSet {
+		t.Run(curTest.Name, func(t *testing.T) {
+			if curTest.SkipTest {
+				t.Skipf("skipped '%s' due to SkipTest being set", curTest.Name)
+			}
+
+			genMap := Convert(curTest.TestStructure, curTest.ConvertOpts...)
+			if !reflect.DeepEqual(genMap, curTest.ExpectedMap) {
+				t.Errorf("generated map not the same as the expected map\nHave: %+v\nWant: %+v", genMap, curTest.ExpectedMap)
+			}
+		})
+	}
+
+	// unexported fields can never be written to
+	if err := Patch(&testStruct, map[string]any{"_counter": 1}, UnexportedFields("_")); err == nil {
+		t.Errorf("expected an error patching an unexported field")
+	}
+}
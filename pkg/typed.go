@@ -0,0 +1,260 @@
+package struct2map
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var ErrNotStruct = errors.New("type is not a structure or a pointer to one")
+
+// what ConvertOf and Into need to know about T, worked out once per type
+type typedPlan[T any] struct {
+	structType reflect.Type         // the structure type; T itself or what T points to
+	isPointer  bool                 // T is a pointer to structType
+	err        error                // set if T cannot be converted at all
+	fields     []planField          // the fields ConvertStruct stores (or walks from) without options, in output order
+	byKey      map[string]planField // the fields holding plain values that Into can assign directly, by key
+	redacted   map[string]bool      // whether the field of each key produced by a single field is redacted
+}
+
+// a field of the structure (or of the structures directly within it) as converted without options
+type planField struct {
+	key   string
+	index []int // for reflect.Value.FieldByIndex; never crosses a pointer
+	typ   reflect.Type
+	tag   fieldTag
+}
+
+var typedPlans sync.Map // a nil *T => *typedPlan[T]
+
+func planOf[T any]() *typedPlan[T] {
+	// a nil *T is a distinct key for every T, without asking reflect for the type on every call
+	if cached, ok := typedPlans.Load((*T)(nil)); ok {
+		return cached.(*typedPlan[T])
+	}
+
+	typ := reflect.TypeFor[T]()
+	plan := &typedPlan[T]{structType: typ}
+	if typ.Kind() == reflect.Pointer {
+		plan.structType = typ.Elem()
+		plan.isPointer = true
+	}
+
+	if plan.structType.Kind() != reflect.Struct {
+		plan.err = fmt.Errorf("struct2map: %w: %s", ErrNotStruct, typ)
+	} else {
+		plan.err = defaultsErr(plan.structType)
+	}
+
+	if plan.err == nil {
+		plan.fields = planFields(&convertConfig{}, plan.structType, nil, "")
+
+		// keys produced by more than one field (ex: through ignoreparents), or that Patch finds some other way (if at
+		// all), are left to Patch
+		keyCount := make(map[string]int, len(plan.fields))
+		for _, field := range plan.fields {
+			keyCount[field.key]++
+		}
+		plan.byKey = make(map[string]planField, len(plan.fields))
+		plan.redacted = make(map[string]bool, len(plan.fields))
+		for _, field := range plan.fields {
+			if keyCount[field.key] == 1 {
+				plan.redacted[field.key] = field.tag.redact
+			}
+			if keyCount[field.key] == 1 && !field.tag.redact && field.typ.Kind() != reflect.Pointer && isScalarType(field.typ) &&
+				reflect.DeepEqual(patchIndex(plan.structType, splitKey(field.key)), field.index) {
+				plan.byKey[field.key] = field
+			}
+		}
+	}
+
+	cached, _ := typedPlans.LoadOrStore((*T)(nil), plan)
+	return cached.(*typedPlan[T])
+}
+
+// lists the fields of t as structToMap walks them, following the structures held directly (not through pointers)
+// by its fields so their keys are worked out once
+func planFields(cfg *convertConfig, t reflect.Type, index []int, parentName string) []planField {
+	var ret []planField
+	fieldTags := cachedFieldTags(t)
+	for pos := 0; pos < t.NumField(); pos++ {
+		tag := configureFieldTag(cfg, fieldTags[pos])
+		if tag.skip {
+			continue
+		}
+
+		// as in structToMap, ignoring parents drops the prefix for this field and every field after it
+		if tag.ignoreParents {
+			parentName = ""
+		}
+
+		keyName := fieldKeyName(cfg, tag)
+		if parentName != "" {
+			keyName = parentName + "." + keyName
+		}
+
+		fieldIndex := append(append([]int(nil), index...), pos)
+		fieldType := t.Field(pos).Type
+		if fieldType.Kind() == reflect.Struct && fieldType != timeType && !tag.redact && !tag.defaultValue.IsValid() {
+			ret = append(ret, planFields(cfg, fieldType, fieldIndex, keyName)...)
+			continue
+		}
+
+		ret = append(ret, planField{key: keyName, index: fieldIndex, typ: fieldType, tag: tag})
+	}
+
+	return ret
+}
+
+// the index of the field Patch assigns the key segs to (following structures only, as assignPath does); nil if the
+// key does not lead to a field that way
+func patchIndex(t reflect.Type, segs []string) []int {
+	var ret []int
+	cfg := &convertConfig{}
+	for idx, seg := range segs {
+		if t.Kind() != reflect.Struct {
+			return nil
+		}
+
+		fieldPath := findField(cfg, t, seg, idx == 0)
+		if fieldPath == nil {
+			return nil
+		}
+		for _, pos := range fieldPath {
+			if t.Kind() != reflect.Struct {
+				return nil
+			}
+			t = t.Field(pos).Type
+		}
+		ret = append(ret, fieldPath...)
+	}
+
+	return ret
+}
+
+// converts v (the structure itself) as structToMap does without options, through the planned fields
+func (plan *typedPlan[T]) toMap(v reflect.Value) map[string]any {
+	cfg := &convertConfig{}
+	ret := make(map[string]any, len(plan.fields))
+	for _, planned := range plan.fields {
+		field := v.FieldByIndex(planned.index)
+		if planned.tag.defaultValue.IsValid() && field.IsZero() {
+			field = copyDefault(planned.tag.defaultValue)
+		}
+
+		if planned.tag.redact {
+			redactToMap(cfg, ret, planned.key, field)
+			continue
+		}
+		valueToMap(cfg, ret, planned.key, field, planned.tag.omitEmpty, false)
+	}
+
+	return ret
+}
+
+// builds the structure (with its defaults) from m into target; values holding exactly the type of the plain field
+// they are keyed to are assigned directly and the rest are applied first, as Patch applies them, since they sort
+// before any key they could change
+func (plan *typedPlan[T]) into(target reflect.Value, m map[string]any) error {
+	// redacted keys hold the replacement ConvertOf stored, never the value
+	for key := range m {
+		if redacted, ok := plan.redacted[key]; redacted || (!ok && isRedactedKey(&convertConfig{}, plan.structType, key)) {
+			m = withoutRedactedKeys(&convertConfig{}, plan.structType, m)
+			break
+		}
+	}
+
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		if m[key] != PATCH_NOOP {
+			keys = append(keys, key)
+		}
+	}
+	working, _ := applyDefaults(&convertConfig{}, target, nil, inputKeys(keys))
+
+	var rest map[string]any
+	for key, val := range m {
+		if planned, ok := plan.byKey[key]; !ok || reflect.TypeOf(val) != planned.typ {
+			if rest == nil {
+				rest = make(map[string]any)
+			}
+			rest[key] = val
+		}
+	}
+	if rest != nil {
+		if err := patchValue(&convertConfig{}, working, working, rest); err != nil {
+			return err
+		}
+	}
+
+	for key, val := range m {
+		if planned, ok := plan.byKey[key]; ok && reflect.TypeOf(val) == planned.typ {
+			working.FieldByIndex(planned.index).Set(reflect.ValueOf(val))
+		}
+	}
+
+	target.Set(working)
+	return nil
+}
+
+// Takes a structure (or a pointer to one) of type T and turns it into a single, flat map exactly as ConvertStruct
+// does; allows passing of various options (see StructConvertOpts constants and the other Option returning functions)
+//
+// Unlike ConvertStruct the type is checked when T is first used and the result of that check is kept for every
+// later call with the same T, along with the keys of its fields; without options those keys are not worked out again.
+//
+// Returns: map[string]any that is representative of v or an error (ex: T is not a structure; v is a nil pointer)
+func ConvertOf[T any](v T, opts ...Option) (map[string]any, error) {
+	plan := planOf[T]()
+	if plan.err != nil {
+		return nil, plan.err
+	}
+
+	objValue := reflect.ValueOf(v)
+	if plan.isPointer {
+		if objValue.IsNil() {
+			return nil, fmt.Errorf("struct2map: ConvertOf got a nil %s", objValue.Type())
+		}
+		objValue = objValue.Elem()
+	}
+
+	if len(opts) == 0 {
+		return plan.toMap(objValue), nil
+	}
+
+	return structToMap(newConvertConfig(opts...), "", objValue), nil
+}
+
+// Takes a flat map (as ConvertOf produces) and builds a new T from it, applying the keys as Patch does; allows passing
+// of various options (see StructConvertOpts constants) which must match the options used to produce the keys. If T is
+// a pointer, a new structure is allocated for it.
+//
+// Without options, values holding exactly the type of the plain field they are keyed to are assigned directly through
+// the fields worked out for T.
+//
+// Returns: the new T and nil on success or the zero T and an error (ex: T is not a structure; a *KeyError for every key that failed)
+func Into[T any](m map[string]any, opts ...Option) (T, error) {
+	var ret T
+
+	plan := planOf[T]()
+	if plan.err != nil {
+		return ret, plan.err
+	}
+
+	target := reflect.New(plan.structType)
+	if len(opts) == 0 {
+		if err := plan.into(target.Elem(), m); err != nil {
+			return ret, err
+		}
+	} else if err := patchWithDefaults("Into", target.Interface(), m, opts...); err != nil {
+		return ret, err
+	}
+
+	if plan.isPointer {
+		return target.Interface().(T), nil
+	}
+
+	return target.Elem().Interface().(T), nil
+}
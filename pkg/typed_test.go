@@ -0,0 +1,280 @@
+package struct2map
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type typedTestServer struct {
+	Host string `struct2map:"host"`
+	Port int    `struct2map:"port"`
+}
+
+type typedTestConfig struct {
+	Name    string            `struct2map:"name"`
+	Server  typedTestServer   `struct2map:"server"`
+	Backup  *typedTestServer  `struct2map:"backup,omitempty"`
+	Tags    []string          `struct2map:"tags"`
+	Labels  map[string]string `struct2map:"labels"`
+	Enabled bool
+}
+
+// covers everything the planned fields have to handle the way structToMap does
+type typedTestPlanned struct {
+	Name   string          `struct2map:"name,default=svc"`
+	Secret string          `struct2map:"secret,redact"`
+	Server typedTestServer `struct2map:"server"`
+	Nested struct {
+		Inner typedTestServer `struct2map:"inner"`
+		Flat  int             `struct2map:"flat,ignoreparents"`
+		After string          `struct2map:"after"`
+	} `struct2map:"nested"`
+	Started time.Time        `struct2map:"started"`
+	Backup  *typedTestServer `struct2map:"backup"`
+	Extra   any              `struct2map:"extra,omitempty"`
+	Vault   *typedTestServer `struct2map:"vault,redact"`
+	hidden  int
+}
+
+func Test_ConvertOf(t *testing.T) {
+	cfg := typedTestConfig{
+		Name:    "svc",
+		Server:  typedTestServer{Host: "localhost", Port: 80},
+		Tags:    []string{"a", "b"},
+		Labels:  map[string]string{"team": "core"},
+		Enabled: true,
+	}
+
+	testSet := []struct {
+		Name     string
+		Convert  func() (map[string]any, error)
+		Expected map[string]any
+		ExpErr   error
+		SkipTest bool
+	}{
+		{
+			Name:     "structure matches ConvertStruct",
+			Convert:  func() (map[string]any, error) { return ConvertOf(cfg) },
+			Expected: ConvertStruct(cfg),
+		},
+		{
+			Name:     "pointer to structure matches ConvertStruct",
+			Convert:  func() (map[string]any, error) { return ConvertOf(&cfg) },
+			Expected: ConvertStruct(&cfg),
+		},
+		{
+			Name:     "options are applied",
+			Convert:  func() (map[string]any, error) { return ConvertOf(cfg, STRUCT_CONVERT_MAPKEY_SNAKE) },
+			Expected: ConvertStruct(cfg, STRUCT_CONVERT_MAPKEY_SNAKE),
+		},
+		{
+			Name:     "options after a cached conversion",
+			Convert:  func() (map[string]any, error) { return ConvertOf(cfg, Include("server.*")) },
+			Expected: map[string]any{"server.host": "localhost", "server.port": 80},
+		},
+		{
+			Name:     "planned fields match ConvertStruct",
+			Convert:  func() (map[string]any, error) { return ConvertOf(newTypedTestPlanned()) },
+			Expected: ConvertStruct(newTypedTestPlanned()),
+		},
+		{
+			Name:     "planned fields of a zero value match ConvertStruct",
+			Convert:  func() (map[string]any, error) { return ConvertOf(&typedTestPlanned{}) },
+			Expected: ConvertStruct(&typedTestPlanned{}),
+		},
+		{
+			Name:    "nil pointer",
+			Convert: func() (map[string]any, error) { return ConvertOf[*typedTestConfig](nil) },
+		},
+		{
+			Name:    "not a structure",
+			Convert: func() (map[string]any, error) { return ConvertOf(map[string]int{"a": 1}) },
+			ExpErr:  ErrNotStruct,
+		},
+		{
+			Name:    "pointer to pointer",
+			Convert: func() (map[string]any, error) { p := &cfg; return ConvertOf(&p) },
+			ExpErr:  ErrNotStruct,
+		},
+	}
+
+	for _, test := range testSet {
+		t.Run(test.Name, func(t *testing.T) {
+			if test.SkipTest {
+				t.Skipf("skipping test")
+			}
+
+			res, err := test.Convert()
+			if test.Expected == nil {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", res)
+				}
+				if test.ExpErr != nil && !errors.Is(err, test.ExpErr) {
+					t.Fatalf("expected %v, got %v", test.ExpErr, err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(res, test.Expected) {
+				t.Fatalf("result mismatch\nexpected: %v\ngot:      %v", test.Expected, res)
+			}
+		})
+	}
+}
+
+func Test_Into(t *testing.T) {
+	cfg := typedTestConfig{
+		Name:    "svc",
+		Server:  typedTestServer{Host: "localhost", Port: 80},
+		Backup:  &typedTestServer{Host: "backup", Port: 81},
+		Tags:    []string{"a", "b"},
+		Labels:  map[string]string{"team": "core"},
+		Enabled: true,
+	}
+
+	t.Run("round trip structure", func(t *testing.T) {
+		res, err := Into[typedTestConfig](ConvertStruct(cfg))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(res, cfg) {
+			t.Fatalf("result mismatch\nexpected: %+v\ngot:      %+v", cfg, res)
+		}
+	})
+
+	t.Run("round trip pointer with options", func(t *testing.T) {
+		res, err := Into[*typedTestConfig](ConvertStruct(cfg, STRUCT_CONVERT_MAPKEY_SNAKE), STRUCT_CONVERT_MAPKEY_SNAKE)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if res == nil || !reflect.DeepEqual(*res, cfg) {
+			t.Fatalf("result mismatch\nexpected: %+v\ngot:      %+v", cfg, res)
+		}
+	})
+
+	t.Run("round trip skips redacted keys", func(t *testing.T) {
+		for _, opts := range [][]Option{nil, {Redact("server.host")}} {
+			flat, err := ConvertOf(newTypedTestPlanned(), opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			res, err := Into[typedTestPlanned](flat, opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// the masks stored for redacted fields never replace the value
+			expected := newTypedTestPlanned()
+			expected.Name, expected.Secret, expected.Vault, expected.hidden = "svc", "", nil, 0
+			if opts != nil {
+				expected.Server.Host = ""
+			}
+			if !reflect.DeepEqual(res, expected) {
+				t.Fatalf("result mismatch\nexpected: %+v\ngot:      %+v", expected, res)
+			}
+		}
+	})
+
+	t.Run("round trip planned fields", func(t *testing.T) {
+		// plain values are assigned directly and everything else (ex: strings to parse, pointers) is patched
+		res, err := Into[typedTestPlanned](map[string]any{"server.host": "h", "server.port": "80", "nested.inner.port": 1, "flat": 2, "after": "a", "backup.host": "b"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// keys ConvertStruct never produces are rejected
+		if _, err := Into[typedTestPlanned](map[string]any{"nested.after": "a"}); !errors.Is(err, ErrUnknownKey) {
+			t.Fatalf("expected ErrUnknownKey, got %v", err)
+		}
+
+		planned := typedTestPlanned{Name: "svc", Server: typedTestServer{Host: "h", Port: 80}, Backup: &typedTestServer{Host: "b"}}
+		planned.Nested.Inner.Port, planned.Nested.Flat, planned.Nested.After = 1, 2, "a"
+		if !reflect.DeepEqual(res, planned) {
+			t.Fatalf("result mismatch\nexpected: %+v\ngot:      %+v", planned, res)
+		}
+	})
+
+	t.Run("bad keys", func(t *testing.T) {
+		res, err := Into[typedTestConfig](map[string]any{"name": "svc", "server.port": "eighty", "nope": 1})
+		var keyErr *KeyError
+		if !errors.As(err, &keyErr) || !errors.Is(err, ErrUnknownKey) || !errors.Is(err, ErrTypeMismatch) {
+			t.Fatalf("expected key errors, got %v", err)
+		}
+		if !reflect.DeepEqual(res, typedTestConfig{}) {
+			t.Fatalf("expected the zero value on error, got %+v", res)
+		}
+	})
+
+	t.Run("not a structure", func(t *testing.T) {
+		if _, err := Into[[]string](map[string]any{"0": "a"}); !errors.Is(err, ErrNotStruct) {
+			t.Fatalf("expected ErrNotStruct, got %v", err)
+		}
+	})
+}
+
+func newTypedTestPlanned() typedTestPlanned {
+	ret := typedTestPlanned{
+		Secret:  "hunter2",
+		Server:  typedTestServer{Host: "h", Port: 1},
+		Started: time.Unix(0, 0).UTC(),
+		Extra:   map[string]int{"a": 1},
+		Vault:   &typedTestServer{Host: "v"},
+		hidden:  3,
+	}
+	ret.Nested.Inner.Port = 2
+	ret.Nested.Flat = 3
+	ret.Nested.After = "after"
+	return ret
+}
+
+func BenchmarkConvertOf(b *testing.B) {
+	planned := newTypedTestPlanned()
+
+	b.Run("ConvertStruct", func(b *testing.B) {
+		b.ReportAllocs()
+		for idx := 0; idx < b.N; idx++ {
+			ConvertStruct(planned)
+		}
+	})
+
+	b.Run("ConvertOf", func(b *testing.B) {
+		b.ReportAllocs()
+		for idx := 0; idx < b.N; idx++ {
+			if _, err := ConvertOf(planned); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkInto(b *testing.B) {
+	// only the keys Patch can apply
+	flat := ConvertStruct(newTypedTestPlanned())
+	for _, key := range []string{"secret", "extra", "vault"} {
+		delete(flat, key)
+	}
+
+	b.Run("Patch", func(b *testing.B) {
+		b.ReportAllocs()
+		for idx := 0; idx < b.N; idx++ {
+			var dest typedTestPlanned
+			if err := Patch(&dest, flat); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Into", func(b *testing.B) {
+		b.ReportAllocs()
+		for idx := 0; idx < b.N; idx++ {
+			if _, err := Into[typedTestPlanned](flat); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
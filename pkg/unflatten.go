@@ -0,0 +1,149 @@
+package struct2map
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var ErrKeyConflict = errors.New("key is both a value and a parent of other keys")
+
+// Limits how large a slice index may be when rebuilding slices from keys (Unflatten) or growing them (Patch and
+// the other reverse conversions); indexes beyond the limit are reported as errors.
+func MaxSliceIndex(limit int) Option {
+	return optionFunc(func(cfg *convertConfig) {
+		cfg.maxSliceIndex = limit
+	})
+}
+
+// Takes an arbitrarily nested map (ex: decoded JSON) and flattens it the same way ConvertStruct flattens
+// structures; allows passing of various options (see StructConvertOpts constants)
+//
+// Returns: map[string]any of the flattened keys and their values
+func Flatten(nested map[string]any, opts ...Option) map[string]any {
+	return convertToMap(newConvertConfig(opts...), nested)
+}
+
+// Takes a flat map with ConvertStruct style keys and rebuilds the nested map[string]any tree it describes;
+// keys are split on "." and a level where every key is a slice index (ex: Tags.0, Tags.1) becomes a []any
+// with any missing indexes left nil. Allows passing of various options (see MaxSliceIndex)
+//
+// Returns: the nested map or an error joining a *KeyError for every key that conflicts with another or holds
+// an index beyond the limit
+func Unflatten(flat map[string]any, opts ...Option) (map[string]any, error) {
+	return unflatten(newConvertConfig(opts...), flat)
+}
+
+func unflatten(cfg *convertConfig, flat map[string]any) (map[string]any, error) {
+	keys := make([]string, 0, len(flat))
+	for k := range flat {
+		keys = append(keys, k)
+	}
+	sortKeys(keys) // parents always sort ahead of their children which makes conflicts easy to report
+
+	root := make(unflattenNode)
+	var errs []error
+UNFLATTEN_KEY_PROC:
+	for _, k := range keys {
+		segs := splitKey(k)
+		node := root
+		for idx, seg := range segs[:len(segs)-1] {
+			child, exists := node[seg]
+			if !exists {
+				newNode := make(unflattenNode)
+				node[seg] = newNode
+				node = newNode
+				continue
+			}
+
+			// keys are sorted so anything already present at a parent position that we did not create is a value
+			childNode, ok := child.(unflattenNode)
+			if !ok {
+				errs = append(errs, &KeyError{Key: k, Err: fmt.Errorf("%w: %s", ErrKeyConflict, joinKey(segs[:idx+1]))})
+				continue UNFLATTEN_KEY_PROC
+			}
+			node = childNode
+		}
+
+		last := segs[len(segs)-1]
+		if _, exists := node[last]; exists {
+			errs = append(errs, &KeyError{Key: k, Err: ErrKeyConflict})
+			continue
+		}
+		node[last] = flat[k]
+	}
+
+	ret, err := buildSlices(cfg, "", root)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return ret.(map[string]any), nil
+}
+
+// the intermediate levels we build; kept as a distinct type so they can never be confused with map values passed in
+type unflattenNode map[string]any
+
+// walks the tree turning every map whose keys are all slice indexes into a []any
+func buildSlices(cfg *convertConfig, keyName string, node unflattenNode) (any, error) {
+	var errs []error
+	for k, v := range node {
+		child, ok := v.(unflattenNode)
+		if !ok {
+			continue
+		}
+
+		childKey := k
+		if keyName != "" {
+			childKey = fmt.Sprintf("%s.%s", keyName, k)
+		}
+
+		built, err := buildSlices(cfg, childKey, child)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		node[k] = built
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	if keyName == "" || len(node) == 0 {
+		return map[string]any(node), nil
+	}
+
+	maxIdx := -1
+	for k := range node {
+		idx, ok := parseSliceIndex(k)
+		if !ok {
+			return map[string]any(node), nil
+		}
+		maxIdx = max(maxIdx, idx)
+	}
+
+	if maxIdx > cfg.sliceIndexLimit() {
+		return nil, &KeyError{Key: fmt.Sprintf("%s.%d", keyName, maxIdx), Err: ErrBadIndex}
+	}
+
+	ret := make([]any, maxIdx+1)
+	for k, v := range node {
+		idx, _ := parseSliceIndex(k)
+		ret[idx] = v
+	}
+
+	return ret, nil
+}
+
+// only canonical non-negative integers are indexes; "01" or "+1" are treated as plain map keys
+func parseSliceIndex(seg string) (int, bool) {
+	idx, err := strconv.Atoi(seg)
+	if err != nil || idx < 0 || strconv.Itoa(idx) != seg {
+		return 0, false
+	}
+
+	return idx, true
+}
@@ -0,0 +1,179 @@
+package struct2map
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func Test_Unflatten(t *testing.T) {
+	testSet := []struct {
+		Name          string
+		FlatMap       map[string]any
+		UnflattenOpts []Option
+		ExpectedMap   map[string]any
+		ExpErrKeys    []string
+		SkipTest      bool
+	}{
+		{
+			Name:        "nested maps and slices",
+			FlatMap:     map[string]any{"name": "svc", "server.port": 80, "tags.0": "a", "tags.1": "b", "servers.0.host": "one", "servers.1.host": "two"},
+			ExpectedMap: map[string]any{"name": "svc", "server": map[string]any{"port": 80}, "tags": []any{"a", "b"}, "servers": []any{map[string]any{"host": "one"}, map[string]any{"host": "two"}}},
+		},
+		{
+			Name:        "sparse indexes are filled with nil",
+			FlatMap:     map[string]any{"tags.0": "a", "tags.2": "c"},
+			ExpectedMap: map[string]any{"tags": []any{"a", nil, "c"}},
+		},
+		{
+			Name:        "mixed and non-canonical indexes stay maps",
+			FlatMap:     map[string]any{"mixed.0": 1, "mixed.x": 2, "padded.01": 3, "nested.0.0": 4},
+			ExpectedMap: map[string]any{"mixed": map[string]any{"0": 1, "x": 2}, "padded": map[string]any{"01": 3}, "nested": []any{[]any{4}}},
+		},
+		{
+			Name:        "map values are left as values",
+			FlatMap:     map[string]any{"value": map[string]any{"a": 1}, "other.a": 2},
+			ExpectedMap: map[string]any{"value": map[string]any{"a": 1}, "other": map[string]any{"a": 2}},
+		},
+		{
+			Name:       "conflicting keys",
+			FlatMap:    map[string]any{"a": 1, "a.b": 2, "c.d": 3, "c.d.e": 4},
+			ExpErrKeys: []string{"a.b", "c.d.e"},
+		},
+		{
+			Name:          "index beyond the limit",
+			FlatMap:       map[string]any{"tags.5": "x"},
+			UnflattenOpts: []Option{MaxSliceIndex(4)},
+			ExpErrKeys:    []string{"tags.5"},
+		},
+	}
+
+	for _, curTest := range testSet {
+		t.Run(curTest.Name, func(t *testing.T) {
+			if curTest.SkipTest {
+				t.Skipf("skipped '%s' due to SkipTest being set", curTest.Name)
+			}
+
+			genMap, err := Unflatten(curTest.FlatMap, curTest.UnflattenOpts...)
+
+			var errKeys []string
+			if err != nil {
+				for _, joined := range err.(interface{ Unwrap() []error }).Unwrap() {
+					var keyErr *KeyError
+					if !errors.As(joined, &keyErr) {
+						t.Fatalf("error is not a *KeyError: %v", joined)
+					}
+					errKeys = append(errKeys, keyErr.Key)
+				}
+				sortKeys(errKeys)
+			}
+			if !reflect.DeepEqual(errKeys, curTest.ExpErrKeys) {
+				t.Errorf("failed keys not as expected\nHave: %v\nWant: %v\nError: %v", errKeys, curTest.ExpErrKeys, err)
+			}
+
+			if !reflect.DeepEqual(genMap, curTest.ExpectedMap) {
+				t.Errorf("generated map not the same as the expected map\nHave: %+v\nWant: %+v", genMap, curTest.ExpectedMap)
+			}
+		})
+	}
+}
+
+func Test_FlattenRoundTrip(t *testing.T) {
+	rawJSON := `{"name":"svc","server":{"port":80,"hosts":["a","b"]},"labels":{"team":"core"},"empty":null,"list":[{"id":1},{"id":2}]}`
+
+	var decoded map[string]any
+	if err := json.Unmarshal([]byte(rawJSON), &decoded); err != nil {
+		t.Fatalf("failed to decode test JSON: %v", err)
+	}
+
+	flat := Flatten(decoded)
+	expected := map[string]any{
+		"name":           "svc",
+		"server.port":    float64(80),
+		"server.hosts.0": "a",
+		"server.hosts.1": "b",
+		"labels.team":    "core",
+		"empty":          nil,
+		"list.0.id":      float64(1),
+		"list.1.id":      float64(2),
+	}
+	if !reflect.DeepEqual(flat, expected) {
+		t.Fatalf("flattened map not the same as the expected map\nHave: %+v\nWant: %+v", flat, expected)
+	}
+
+	nested, err := Unflatten(flat)
+	if err != nil {
+		t.Fatalf("failed to unflatten: %v", err)
+	}
+	if !reflect.DeepEqual(nested, decoded) {
+		t.Errorf("round trip did not reproduce the decoded JSON\nHave: %+v\nWant: %+v", nested, decoded)
+	}
+
+	if upper := Flatten(decoded, STRUCT_CONVERT_MAPKEY_TOUPPER); upper["SERVER.HOSTS.1"] != "b" {
+		t.Errorf("name modifiers not applied when flattening: %+v", upper)
+	}
+}
+
+// nested containers inside maps and slices of structures are namespaced by Convert, but stored whole by ConvertStruct
+func Test_NestedContainers(t *testing.T) {
+	type nestedContainers struct {
+		MapOfSlices   map[string][]int
+		SliceOfSlices [][]string
+		AnyStruct     any
+		NilPtrs       []*int
+	}
+
+	testStructure := nestedContainers{
+		MapOfSlices:   map[string][]int{"a": {1, 2}},
+		SliceOfSlices: [][]string{{"x"}, {"y", "z"}},
+		AnyStruct:     diffTestServer{Host: "h", Port: 1},
+		NilPtrs:       []*int{nil},
+	}
+
+	testSet := []struct {
+		Name        string
+		ConvertFunc func(any) map[string]any
+		ExpectedMap map[string]any
+		SkipTest    bool
+	}{
+		{
+			Name:        "ConvertStruct",
+			ConvertFunc: func(obj any) map[string]any { return ConvertStruct(obj) },
+			ExpectedMap: map[string]any{
+				"MapOfSlices.a":   []int{1, 2},
+				"SliceOfSlices.0": []string{"x"},
+				"SliceOfSlices.1": []string{"y", "z"},
+				"AnyStruct":       diffTestServer{Host: "h", Port: 1},
+				"NilPtrs.0":       nil,
+			},
+		},
+		{
+			Name:        "Convert",
+			ConvertFunc: func(obj any) map[string]any { return Convert(obj) },
+			ExpectedMap: map[string]any{
+				"MapOfSlices.a.0":   1,
+				"MapOfSlices.a.1":   2,
+				"SliceOfSlices.0.0": "x",
+				"SliceOfSlices.1.0": "y",
+				"SliceOfSlices.1.1": "z",
+				"AnyStruct.Host":    "h",
+				"AnyStruct.Port":    1,
+				"NilPtrs.0":         nil,
+			},
+		},
+	}
+
+	for _, curTest := range testSet {
+		t.Run(curTest.Name, func(t *testing.T) {
+			if curTest.SkipTest {
+				t.Skipf("skipped '%s' due to SkipTest being set", curTest.Name)
+			}
+
+			genMap := curTest.ConvertFunc(testStructure)
+			if !reflect.DeepEqual(genMap, curTest.ExpectedMap) {
+				t.Errorf("generated map not the same as the expected map\nHave: %+v\nWant: %+v", genMap, curTest.ExpectedMap)
+			}
+		})
+	}
+}
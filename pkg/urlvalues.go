@@ -0,0 +1,97 @@
+package struct2map
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+type URLSliceEncoding uint
+
+// How ToURLValues encodes slices of plain values; URLSliceEncoding values are Options themselves
+const (
+	URL_SLICE_INDEXED  URLSliceEncoding = iota // one key per index as flattened (ex: tag.0=a&tag.1=b); the default
+	URL_SLICE_REPEATED                         // the key repeated for every item (ex: tag=a&tag=b)
+	URL_SLICE_BRACKETS                         // the key suffixed with [] and repeated for every item (ex: tag[]=a&tag[]=b)
+)
+
+func (enc URLSliceEncoding) apply(cfg *convertConfig) {
+	cfg.urlSliceEncoding = enc
+}
+
+// Takes a structure (or any value Convert accepts) and encodes it as url.Values keyed by the flattened keys; allows
+// passing of various options (see StructConvertOpts and URLSliceEncoding constants)
+//
+// Values are formatted the same way ToEnv formats them; nil values are encoded as an empty value.
+//
+// Returns: the url.Values or nil on error (see Convert)
+func ToURLValues(obj any, opts ...Option) url.Values {
+	cfg := newConvertConfig(opts...)
+	cfg.keepSlices = cfg.urlSliceEncoding != URL_SLICE_INDEXED
+
+	flat := convertToMap(cfg, obj)
+	if flat == nil {
+		return nil
+	}
+
+	ret := make(url.Values, len(flat))
+	for k, v := range flat {
+		items, ok := formatSliceValues(cfg, v)
+		if !ok {
+			ret.Set(k, formatValue(cfg, v))
+			continue
+		}
+
+		if cfg.urlSliceEncoding == URL_SLICE_BRACKETS {
+			k += "[]"
+		}
+		for _, item := range items {
+			ret.Add(k, item)
+		}
+	}
+
+	return ret
+}
+
+// Populates the structure pointed to by dest from url.Values keyed the way ToURLValues keys them; allows passing of
+// various options (see StructConvertOpts constants), which must match the options used to encode the values
+//
+// All three URLSliceEncoding styles are understood regardless of the options passed; repeated keys and keys ending
+// in [] are loaded as a whole slice while indexed keys set individual items. Keys are then applied as Patch applies them.
+//
+// Returns: nil on success or an error joining a *KeyError for every key that could not be applied; on error dest is
+// left untouched
+func FromURLValues(values url.Values, dest any, opts ...Option) error {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys) // only matters when the same slice arrives in more than one style
+
+	changes := make(map[string]any, len(values))
+	for _, k := range keys {
+		vals := values[k]
+		name, isBracket := strings.CutSuffix(k, "[]")
+
+		var change any = vals
+		if len(vals) == 1 && !isBracket {
+			change = vals[0]
+		}
+
+		// the same slice may arrive both repeated and bracketed; keep every item
+		if existing, ok := changes[name]; ok {
+			change = append(toStrings(existing), toStrings(change)...)
+		}
+		changes[name] = change
+	}
+
+	return patchWithDefaults("FromURLValues", dest, changes, opts...)
+}
+
+func toStrings(val any) []string {
+	if str, ok := val.(string); ok {
+		return []string{str}
+	}
+
+	return append([]string(nil), val.([]string)...)
+}
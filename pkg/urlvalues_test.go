@@ -0,0 +1,118 @@
+package struct2map
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+type urlTestFilter struct {
+	Query  string            `struct2map:"q"`
+	Tags   []string          `struct2map:"tag"`
+	Limits []int             `struct2map:"limit"`
+	Page   *int              `struct2map:"page"`
+	Extra  map[string]string `struct2map:"extra"`
+	Window [2]int            `struct2map:"window"`
+}
+
+func Test_ToURLValues(t *testing.T) {
+	testStruct := urlTestFilter{
+		Query:  "a b&c",
+		Tags:   []string{"x", "y"},
+		Limits: []int{},
+		Extra:  map[string]string{"sort": "asc"},
+		Window: [2]int{10, 20},
+	}
+
+	testSet := []struct {
+		Name        string
+		URLOpts     []Option
+		ExpectedEnc string
+		SkipTest    bool
+	}{
+		{
+			Name:        "indexed by default",
+			ExpectedEnc: "extra.sort=asc&page=&q=a+b%26c&tag.0=x&tag.1=y&window=10&window=20",
+		},
+		{
+			Name:        "repeated",
+			URLOpts:     []Option{URL_SLICE_REPEATED},
+			ExpectedEnc: "extra.sort=asc&page=&q=a+b%26c&tag=x&tag=y&window=10&window=20",
+		},
+		{
+			Name:        "brackets",
+			URLOpts:     []Option{URL_SLICE_BRACKETS},
+			ExpectedEnc: "extra.sort=asc&page=&q=a+b%26c&tag%5B%5D=x&tag%5B%5D=y&window%5B%5D=10&window%5B%5D=20",
+		},
+	}
+
+	for _, curTest := range testSet {
+		t.Run(curTest.Name, func(t *testing.T) {
+			if curTest.SkipTest {
+				t.Skipf("skipped '%s' due to SkipTest being set", curTest.Name)
+			}
+
+			genValues := ToURLValues(testStruct, curTest.URLOpts...)
+			if genValues.Encode() != curTest.ExpectedEnc {
+				t.Errorf("generated values not the same as the expected values\nHave: %s\nWant: %s", genValues.Encode(), curTest.ExpectedEnc)
+			}
+
+			// every encoding decodes back to the same structure
+			var decoded urlTestFilter
+			if err := FromURLValues(genValues, &decoded); err != nil {
+				t.Fatalf("unexpected error decoding: %v", err)
+			}
+			if !reflect.DeepEqual(decoded, urlTestFilter{Query: testStruct.Query, Tags: testStruct.Tags, Extra: testStruct.Extra, Window: testStruct.Window}) {
+				t.Errorf("decoded value not the same as the encoded value: %+v", decoded)
+			}
+		})
+	}
+}
+
+func Test_FromURLValues(t *testing.T) {
+	testSet := []struct {
+		Name        string
+		Query       string
+		ExpectedVal urlTestFilter
+		ExpErrKeys  []string
+		SkipTest    bool
+	}{
+		{
+			Name:        "single repeated value for a slice",
+			Query:       "tag=only&limit=5&page=2",
+			ExpectedVal: urlTestFilter{Tags: []string{"only"}, Limits: []int{5}, Page: func() *int { p := 2; return &p }()},
+		},
+		{
+			Name:        "mixed repeated and bracketed",
+			Query:       "tag=a&tag[]=b&limit.1=3",
+			ExpectedVal: urlTestFilter{Tags: []string{"a", "b"}, Limits: []int{0, 3}},
+		},
+		{
+			Name:       "bad values",
+			Query:      "limit=1&limit=x&page=y&unknown=1",
+			ExpErrKeys: []string{"limit", "page", "unknown"},
+		},
+	}
+
+	for _, curTest := range testSet {
+		t.Run(curTest.Name, func(t *testing.T) {
+			if curTest.SkipTest {
+				t.Skipf("skipped '%s' due to SkipTest being set", curTest.Name)
+			}
+
+			values, err := url.ParseQuery(curTest.Query)
+			if err != nil {
+				t.Fatalf("bad test query: %v", err)
+			}
+
+			var decoded urlTestFilter
+			err = FromURLValues(values, &decoded)
+			if errKeys := keyErrorKeys(t, err); !reflect.DeepEqual(errKeys, curTest.ExpErrKeys) {
+				t.Errorf("failed keys not as expected\nHave: %v\nWant: %v\nError: %v", errKeys, curTest.ExpErrKeys, err)
+			}
+			if !reflect.DeepEqual(decoded, curTest.ExpectedVal) {
+				t.Errorf("decoded value not the same as the expected value\nHave: %+v\nWant: %+v", decoded, curTest.ExpectedVal)
+			}
+		})
+	}
+}
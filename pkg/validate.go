@@ -0,0 +1,172 @@
+package struct2map
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// A problem Validate found with a single key
+type ValidationError struct {
+	Key      string
+	Expected reflect.Type // the type the key is assigned to (or the container it failed in); nil if the key matches nothing
+	Err      error        // one of ErrUnknownKey, ErrTypeMismatch, ErrOutOfRange, ErrBadIndex or ErrMissingRequired, possibly wrapped
+}
+
+func (e ValidationError) Error() string {
+	if e.Expected == nil {
+		return fmt.Sprintf("%s: %v", e.Key, e.Err)
+	}
+
+	return fmt.Sprintf("%s: %v (expected %s)", e.Key, e.Err, e.Expected)
+}
+
+func (e ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Takes a flat map (ex: untrusted input from a form post) and checks it could be applied to a structure of type T
+// without error; allows passing of various options (see StructConvertOpts constants) which must match the options
+// used to produce the keys
+//
+// Every key is checked on its own, exactly as Patch (or Into) would apply it, so the errors found are reported for
+// every key rather than stopping at the first: keys matching no field, values that cannot be converted to the field
+// type or do not fit in it and malformed (or too large) slice indexes. Redacted keys (see Redact) are not checked, as
+// Into skips them. Fields tagged required, found by following the
+// nested structures of T, must have a value at their key or below it, unless a key above it holds nil (ex: a nil
+// pointer to the structure holding the field, as ConvertStruct outputs it).
+//
+// Returns: []ValidationError in key order (followed by the missing required fields in field order) or nil if m is valid
+func Validate[T any](m map[string]any, opts ...Option) []ValidationError {
+	plan := planOf[T]()
+	if plan.err != nil {
+		return []ValidationError{{Err: plan.err}}
+	}
+
+	cfg := newConvertConfig(opts...)
+	zero := reflect.New(plan.structType).Elem()
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sortKeys(keys)
+
+	var ret []ValidationError
+	for _, k := range keys {
+		// redacted keys hold the replacement ConvertStruct stored, which Into skips
+		if m[k] == PATCH_NOOP || isRedactedKey(cfg, plan.structType, k) {
+			continue
+		}
+
+		if _, err := assignPath(cfg, zero, splitKey(k), m[k], true); err != nil {
+			ret = append(ret, ValidationError{Key: k, Expected: typeAtPath(cfg, plan.structType, splitKey(k)), Err: err})
+		}
+	}
+
+	for _, required := range requiredFields(cfg, plan.structType, nil, map[reflect.Type]bool{}) {
+		// a nil pointer above the field (stored at the pointer's own key) leaves nothing to require, and the
+		// replacement stored for a redacted value above it stands in for it
+		found := false
+		for _, k := range keys {
+			if keyHasPrefix(k, required.key) || (keyHasPrefix(required.key, k) && (m[k] == nil || isRedactedKey(cfg, plan.structType, k))) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ret = append(ret, ValidationError{Key: required.key, Expected: required.fieldType, Err: ErrMissingRequired})
+		}
+	}
+
+	return ret
+}
+
+// follows the key segments through t as assignPath does, returning the type of the last value reached; nil if the
+// first segment matches nothing
+func typeAtPath(cfg *convertConfig, t reflect.Type, segs []string) reflect.Type {
+	isRoot := true
+	for len(segs) > 0 {
+		switch t.Kind() {
+		case reflect.Pointer:
+			t = t.Elem()
+			continue
+		case reflect.Struct:
+			fieldPath := findField(cfg, t, segs[0], isRoot)
+			if fieldPath == nil {
+				if isRoot {
+					return nil
+				}
+				return t
+			}
+			for _, pos := range fieldPath {
+				for t.Kind() == reflect.Pointer {
+					t = t.Elem()
+				}
+				t = t.Field(pos).Type
+			}
+		case reflect.Map:
+			if _, err := parseMapKey(t.Key(), segs[0]); err != nil {
+				return t
+			}
+			t = t.Elem()
+		case reflect.Slice, reflect.Array:
+			idx, ok := parseSliceIndex(segs[0])
+			if !ok || idx > cfg.sliceIndexLimit() || (t.Kind() == reflect.Array && idx >= t.Len()) {
+				return t
+			}
+			t = t.Elem()
+		default:
+			return t // nothing nested within; interfaces can hold anything
+		}
+
+		segs = segs[1:]
+		isRoot = false
+	}
+
+	return t
+}
+
+// a field tagged required and the key it is found at
+type requiredField struct {
+	key       string
+	fieldType reflect.Type
+}
+
+// lists the fields tagged required in t and the structures nested within it (directly or through pointers)
+func requiredFields(cfg *convertConfig, t reflect.Type, parentSegs []string, inProgress map[reflect.Type]bool) []requiredField {
+	if inProgress[t] {
+		return nil
+	}
+	inProgress[t] = true
+	defer delete(inProgress, t)
+
+	var ret []requiredField
+	fieldTags := cachedFieldTags(t)
+	for pos := 0; pos < t.NumField(); pos++ {
+		tag := configureFieldTag(cfg, fieldTags[pos])
+		if tag.skip || tag.unexported {
+			continue
+		}
+
+		// as in structToMap, ignoring parents drops the prefix for this field and every field after it
+		if tag.ignoreParents {
+			parentSegs = nil
+		}
+
+		segs := appendSeg(parentSegs, fieldKeyName(cfg, tag))
+		fieldType := t.Field(pos).Type
+		if tag.required && !tag.hasDefault {
+			ret = append(ret, requiredField{key: joinKey(segs), fieldType: fieldType})
+		}
+
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		// nothing within a redacted structure is ever output
+		if fieldType.Kind() == reflect.Struct && fieldType != timeType && !tag.redact {
+			ret = append(ret, requiredFields(cfg, fieldType, segs, inProgress)...)
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,164 @@
+package struct2map
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type validateTestServer struct {
+	Host string `struct2map:"host,required"`
+	Port uint16 `struct2map:"port"`
+}
+
+type validateTestConfig struct {
+	Name    string                        `struct2map:"name,required"`
+	Server  validateTestServer            `struct2map:"server"`
+	Backup  *validateTestServer           `struct2map:"backup"`
+	Servers map[string]validateTestServer `struct2map:"servers"`
+	Tags    []string                      `struct2map:"tags"`
+	Fixed   [2]int8                       `struct2map:"fixed"`
+	Labels  map[int]string                `struct2map:"labels"`
+	Flat    bool                          `struct2map:"flat,ignoreparents"`
+	Extra   any                           `struct2map:"extra"`
+}
+
+func Test_Validate(t *testing.T) {
+	validBase := map[string]any{"name": "svc", "server.host": "localhost", "backup.host": "backup"}
+	withBase := func(extra map[string]any) map[string]any {
+		ret := map[string]any{}
+		for k, v := range validBase {
+			ret[k] = v
+		}
+		for k, v := range extra {
+			ret[k] = v
+		}
+		return ret
+	}
+
+	uint16Type := reflect.TypeOf(uint16(0))
+	testSet := []struct {
+		Name     string
+		Input    map[string]any
+		Opts     []Option
+		Expected []ValidationError
+		SkipTest bool
+	}{
+		{
+			Name:  "valid",
+			Input: withBase(map[string]any{"server.port": "8080", "servers.primary.port": 80, "tags.3": "d", "fixed.1": int64(-5), "labels.7": "seven", "flat": "true", "extra.any.thing": 1, "name": PATCH_NOOP}),
+		},
+		{
+			Name:  "unknown keys",
+			Input: withBase(map[string]any{"nope": 1, "server.nope": 1, "tags.0.nope": "x"}),
+			Expected: []ValidationError{
+				{Key: "nope", Err: ErrUnknownKey},
+				{Key: "server.nope", Expected: reflect.TypeOf(validateTestServer{}), Err: ErrUnknownKey},
+				{Key: "tags.0.nope", Expected: stringType, Err: ErrUnknownKey},
+			},
+		},
+		{
+			Name:  "type mismatches and ranges",
+			Input: withBase(map[string]any{"server.port": "eighty", "servers.a.port": 70000, "fixed.0": 300, "flat": []int{1}, "name": 5}),
+			Expected: []ValidationError{
+				{Key: "fixed.0", Expected: reflect.TypeOf(int8(0)), Err: ErrOutOfRange},
+				{Key: "flat", Expected: reflect.TypeOf(false), Err: ErrTypeMismatch},
+				{Key: "name", Expected: stringType, Err: ErrTypeMismatch},
+				{Key: "server.port", Expected: uint16Type, Err: ErrTypeMismatch},
+				{Key: "servers.a.port", Expected: uint16Type, Err: ErrOutOfRange},
+			},
+		},
+		{
+			Name:  "malformed indexes and map keys",
+			Input: withBase(map[string]any{"tags.x": "a", "tags.-1": "a", "tags.99999999": "a", "fixed.2": 1, "labels.seven": "7"}),
+			Expected: []ValidationError{
+				{Key: "fixed.2", Expected: reflect.TypeOf([2]int8{}), Err: ErrBadIndex},
+				{Key: "labels.seven", Expected: reflect.TypeOf(map[int]string{}), Err: ErrTypeMismatch},
+				{Key: "tags.-1", Expected: reflect.TypeOf([]string{}), Err: ErrBadIndex},
+				{Key: "tags.99999999", Expected: reflect.TypeOf([]string{}), Err: ErrBadIndex},
+				{Key: "tags.x", Expected: reflect.TypeOf([]string{}), Err: ErrBadIndex},
+			},
+		},
+		{
+			Name:  "missing required",
+			Input: map[string]any{"server.port": 80},
+			Expected: []ValidationError{
+				{Key: "name", Expected: stringType, Err: ErrMissingRequired},
+				{Key: "server.host", Expected: stringType, Err: ErrMissingRequired},
+				{Key: "backup.host", Expected: stringType, Err: ErrMissingRequired},
+			},
+		},
+		{
+			Name:  "options",
+			Input: map[string]any{"NAME": "svc", "SERVER.HOST": "a", "BACKUP.HOST": "b", "SERVER.PORT": -1},
+			Opts:  []Option{STRUCT_CONVERT_MAPKEY_TOUPPER},
+			Expected: []ValidationError{
+				{Key: "SERVER.PORT", Expected: uint16Type, Err: ErrOutOfRange},
+			},
+		},
+	}
+
+	for _, test := range testSet {
+		t.Run(test.Name, func(t *testing.T) {
+			if test.SkipTest {
+				t.Skipf("skipping test")
+			}
+
+			res := Validate[validateTestConfig](test.Input, test.Opts...)
+			if len(res) != len(test.Expected) {
+				t.Fatalf("expected %d errors, got %d: %v", len(test.Expected), len(res), res)
+			}
+			for idx, exp := range test.Expected {
+				if res[idx].Key != exp.Key || res[idx].Expected != exp.Expected || !errors.Is(res[idx], exp.Err) {
+					t.Errorf("error %d mismatch\nexpected: %v (%v)\ngot:      %v (%v)", idx, exp, exp.Expected, res[idx], res[idx].Expected)
+				}
+			}
+		})
+	}
+}
+
+type validateTestOutput struct {
+	Name   string             `struct2map:"name,required"`
+	Port   int                `struct2map:"port"`
+	Auth   validateTestServer `struct2map:"auth,redact"`
+	Token  string             `struct2map:"token,redact,required"`
+	Server validateTestServer `struct2map:"server"`
+	Meta   struct {
+		ID   string `struct2map:"id,ignoreparents"`
+		Zone string `struct2map:"zone"`
+	} `struct2map:"meta"`
+}
+
+// the library's own output is always valid, redacted keys and keys flattened by ignoreparents included
+func Test_ValidateConvertOutput(t *testing.T) {
+	testStruct := validateTestOutput{Name: "svc", Port: 80, Auth: validateTestServer{Host: "a"}, Token: "t", Server: validateTestServer{Host: "s"}}
+	testStruct.Meta.ID, testStruct.Meta.Zone = "i", "z"
+
+	for _, opts := range [][]Option{nil, {Redact("port")}, {Redact("server")}} {
+		flat := Convert(testStruct, opts...)
+		if res := Validate[validateTestOutput](flat, opts...); res != nil {
+			t.Errorf("unexpected errors validating %v: %v", flat, res)
+		}
+		if _, err := Into[validateTestOutput](flat, opts...); err != nil {
+			t.Errorf("unexpected error applying %v: %v", flat, err)
+		}
+	}
+}
+
+func Test_ValidateNotStruct(t *testing.T) {
+	res := Validate[[]string](map[string]any{"0": "a"})
+	if len(res) != 1 || !errors.Is(res[0], ErrNotStruct) {
+		t.Fatalf("expected ErrNotStruct, got %v", res)
+	}
+}
+
+// anything Validate accepts, Into must accept as well
+func Test_ValidateMatchesInto(t *testing.T) {
+	input := map[string]any{"name": "svc", "server.host": "a", "server.port": "80", "backup.host": "b", "tags.1": "x", "servers.p.host": "c"}
+	if res := Validate[validateTestConfig](input); res != nil {
+		t.Fatalf("unexpected errors: %v", res)
+	}
+	if _, err := Into[validateTestConfig](input); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}